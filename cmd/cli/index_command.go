@@ -325,6 +325,7 @@ func parseRepository(path string, workers int, verbose bool, logger *utils.Logge
 
 	// Map to schema
 	mapper := schema.NewSchemaMapper()
+	mapper.SetCorpus(repoName)
 	var schemaFiles []schema.File
 	var allEdges []schema.DependencyEdge
 	var mappingErrors []schema.ParseError
@@ -40,6 +40,7 @@ func main() {
 		Version: Version,
 		Commands: []*cli.Command{
 			createParseCommand(),
+			createTestMatrixCommand(),
 			{
 				Name:  "upload",
 				Usage: "Upload repository to CodeAtlas server",
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/urfave/cli/v2"
+)
+
+// pairCoverage mirrors internal/parser/testutil.PairCoverage. It is
+// redeclared here (rather than imported) so the CLI only depends on the
+// stable JSON artifact shape, not on the parser package's test harness.
+type pairCoverage struct {
+	SourceLanguage string `json:"source_language"`
+	TargetLanguage string `json:"target_language"`
+	FixturePath    string `json:"fixture_path"`
+	FixtureExists  bool   `json:"fixture_exists"`
+	Passed         bool   `json:"passed"`
+}
+
+// createTestMatrixCommand creates the test-matrix CLI command
+func createTestMatrixCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "test-matrix",
+		Usage: "Show cross-language fixture coverage",
+		Description: `Print the cross-language "calls-to" fixture coverage matrix generated by
+   running the parser package's tests (go test ./internal/parser/...).
+
+   Each row is a (source language, target language) pair from
+   internal/parser/cross_language_matrix_test.go, showing whether a fixture
+   file exists for it and whether its assertions passed on the last run.
+
+EXAMPLES:
+   # Generate the artifact, then print it
+   go test ./internal/parser/... && codeatlas test-matrix
+
+   # Read an artifact from a non-default location
+   codeatlas test-matrix --input tests/fixtures/.coverage/cross_language_matrix.json`,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "input",
+				Aliases: []string{"i"},
+				Usage:   "Path to the coverage artifact",
+				Value:   "tests/fixtures/.coverage/cross_language_matrix.json",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			path := c.String("input")
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return fmt.Errorf("failed to read coverage artifact %s (run `go test ./internal/parser/...` first): %w", path, err)
+			}
+
+			var coverage []pairCoverage
+			if err := json.Unmarshal(data, &coverage); err != nil {
+				return fmt.Errorf("failed to parse coverage artifact %s: %w", path, err)
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+			fmt.Fprintln(w, "SOURCE\tTARGET\tFIXTURE\tPASSED")
+			for _, row := range coverage {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", row.SourceLanguage, row.TargetLanguage, yesNo(row.FixtureExists), yesNo(row.Passed))
+			}
+			return w.Flush()
+		},
+	}
+}
+
+// yesNo renders a bool as the CLI's "yes"/"no" table convention.
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
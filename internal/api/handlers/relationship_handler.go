@@ -11,19 +11,21 @@ import (
 
 // RelationshipHandler handles relationship query operations
 type RelationshipHandler struct {
-	db         *models.DB
-	symbolRepo *models.SymbolRepository
-	fileRepo   *models.FileRepository
-	edgeRepo   *models.EdgeRepository
+	db          *models.DB
+	symbolRepo  *models.SymbolRepository
+	fileRepo    *models.FileRepository
+	edgeRepo    *models.EdgeRepository
+	astNodeRepo *models.ASTNodeRepository
 }
 
 // NewRelationshipHandler creates a new relationship handler
 func NewRelationshipHandler(db *models.DB) *RelationshipHandler {
 	return &RelationshipHandler{
-		db:         db,
-		symbolRepo: models.NewSymbolRepository(db),
-		fileRepo:   models.NewFileRepository(db),
-		edgeRepo:   models.NewEdgeRepository(db),
+		db:          db,
+		symbolRepo:  models.NewSymbolRepository(db),
+		fileRepo:    models.NewFileRepository(db),
+		edgeRepo:    models.NewEdgeRepository(db),
+		astNodeRepo: models.NewASTNodeRepository(db),
 	}
 }
 
@@ -59,6 +61,32 @@ type Dependency struct {
 	Signature    string `json:"signature,omitempty"`
 }
 
+// CrossReferenceNode describes the AST node span backing a cross-referenced symbol
+type CrossReferenceNode struct {
+	Ticket    string `json:"ticket,omitempty"`
+	NodeType  string `json:"node_type,omitempty"`
+	StartLine int    `json:"start_line"`
+	EndLine   int    `json:"end_line"`
+	StartByte int    `json:"start_byte"`
+	EndByte   int    `json:"end_byte"`
+}
+
+// CrossReference pairs a DependencyEdge-shaped relationship with the
+// referenced symbol and its declaration node, if one was found
+type CrossReference struct {
+	EdgeType string              `json:"edge_type"`
+	Symbol   RelatedSymbol       `json:"symbol"`
+	Node     *CrossReferenceNode `json:"node,omitempty"`
+}
+
+// CrossReferencesResponse is the response for GET /api/v1/xrefs?ticket=...
+type CrossReferencesResponse struct {
+	Ticket     string           `json:"ticket"`
+	Symbol     RelatedSymbol    `json:"symbol"`
+	References []CrossReference `json:"references"`
+	Total      int              `json:"total"`
+}
+
 // SymbolsResponse represents the response for file symbols query
 type SymbolsResponse struct {
 	Symbols []SymbolInfo `json:"symbols"`
@@ -532,6 +560,143 @@ func (h *RelationshipHandler) GetFileSymbols(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
+// CrossReferences handles GET /api/v1/xrefs?ticket=...
+// Resolves a Kythe-style ticket (see models.FormatTicket) to its symbol and
+// returns every DependencyEdge where that symbol is source or target,
+// together with the declaration ASTNode span of the counterpart symbol.
+// Ticket resolution is a plain lookup by indexed column, so unlike the other
+// relationship queries this has no AGE Cypher path to fall back from.
+func (h *RelationshipHandler) CrossReferences(c *gin.Context) {
+	// Tickets embed a scheme and raw query characters (kythe://corpus?lang=...#sig),
+	// so they're taken as a query parameter rather than a path segment.
+	ticket := c.Query("ticket")
+	if ticket == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "ticket query parameter is required",
+		})
+		return
+	}
+
+	ctx := context.Background()
+
+	symbol, err := h.symbolRepo.GetByTicket(ctx, ticket)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to resolve ticket",
+			"details": err.Error(),
+		})
+		return
+	}
+	if symbol == nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "No symbol found for ticket",
+		})
+		return
+	}
+
+	outgoing, err := h.edgeRepo.GetBySourceID(ctx, symbol.SymbolID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve outgoing edges",
+			"details": err.Error(),
+		})
+		return
+	}
+	incoming, err := h.edgeRepo.GetByTargetID(ctx, symbol.SymbolID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve incoming edges",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	edges := make([]*models.Edge, 0, len(outgoing)+len(incoming))
+	edges = append(edges, outgoing...)
+	edges = append(edges, incoming...)
+
+	references := make([]CrossReference, 0, len(edges))
+	for _, edge := range edges {
+		counterpartID := edge.TargetID
+		if edge.SourceID != symbol.SymbolID {
+			sourceID := edge.SourceID
+			counterpartID = &sourceID
+		}
+		if counterpartID == nil {
+			continue
+		}
+
+		counterpart, err := h.symbolRepo.GetByID(ctx, *counterpartID)
+		if err != nil || counterpart == nil {
+			continue
+		}
+
+		filePath := ""
+		if file, err := h.fileRepo.GetByID(ctx, counterpart.FileID); err == nil && file != nil {
+			filePath = file.Path
+		}
+
+		ref := CrossReference{
+			EdgeType: edge.EdgeType,
+			Symbol: RelatedSymbol{
+				SymbolID:  counterpart.SymbolID,
+				Name:      counterpart.Name,
+				Kind:      counterpart.Kind,
+				FilePath:  filePath,
+				Signature: counterpart.Signature,
+			},
+		}
+
+		if node, err := h.findDeclarationNode(ctx, counterpart); err == nil && node != nil {
+			ref.Node = &CrossReferenceNode{
+				Ticket:    node.Ticket,
+				NodeType:  node.Type,
+				StartLine: node.StartLine,
+				EndLine:   node.EndLine,
+				StartByte: node.StartByte,
+				EndByte:   node.EndByte,
+			}
+		}
+
+		references = append(references, ref)
+	}
+
+	symbolFilePath := ""
+	if file, err := h.fileRepo.GetByID(ctx, symbol.FileID); err == nil && file != nil {
+		symbolFilePath = file.Path
+	}
+
+	response := CrossReferencesResponse{
+		Ticket: ticket,
+		Symbol: RelatedSymbol{
+			SymbolID:  symbol.SymbolID,
+			Name:      symbol.Name,
+			Kind:      symbol.Kind,
+			FilePath:  symbolFilePath,
+			Signature: symbol.Signature,
+		},
+		References: references,
+		Total:      len(references),
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// findDeclarationNode looks up the AST node whose span exactly matches a
+// symbol's declaration, used to surface the node backing a cross-reference.
+func (h *RelationshipHandler) findDeclarationNode(ctx context.Context, symbol *models.Symbol) (*models.ASTNode, error) {
+	nodes, err := h.astNodeRepo.GetByFileID(ctx, symbol.FileID)
+	if err != nil {
+		return nil, err
+	}
+	for _, node := range nodes {
+		if node.StartLine == symbol.StartLine && node.EndLine == symbol.EndLine {
+			return node, nil
+		}
+	}
+	return nil, nil
+}
+
 // parseAgtypeString parses an agtype JSON string value
 // AGE returns values as JSON, so "value" becomes value
 func parseAgtypeString(agtype string) string {
@@ -101,6 +101,7 @@ func (s *Server) RegisterRoutes(r *gin.Engine) {
 		v1.GET("/symbols/:id/callees", s.relationshipHandler.GetCallees)
 		v1.GET("/symbols/:id/dependencies", s.relationshipHandler.GetDependencies)
 		v1.GET("/files/:id/symbols", s.relationshipHandler.GetFileSymbols)
+		v1.GET("/xrefs", s.relationshipHandler.CrossReferences)
 
 		// File endpoints
 		v1.POST("/files", s.createFile)
@@ -163,6 +163,7 @@ func (w *Writer) WriteSymbols(ctx context.Context, symbols []schema.Symbol) (*Wr
 			EndByte:         symbol.Span.EndByte,
 			Docstring:       symbol.Docstring,
 			SemanticSummary: symbol.SemanticSummary,
+			Ticket:          symbol.Ticket,
 		}
 		modelSymbols = append(modelSymbols, modelSymbol)
 	}
@@ -223,6 +224,7 @@ func (w *Writer) WriteASTNodes(ctx context.Context, nodes []schema.ASTNode) (*Wr
 			EndByte:    node.Span.EndByte,
 			Text:       node.Text,
 			Attributes: node.Attributes,
+			Ticket:     node.Ticket,
 		}
 		modelNodes = append(modelNodes, modelNode)
 	}
@@ -423,6 +425,7 @@ func (w *Writer) WriteSymbolsWithTransaction(ctx context.Context, symbols []sche
 			EndByte:         symbol.Span.EndByte,
 			Docstring:       symbol.Docstring,
 			SemanticSummary: symbol.SemanticSummary,
+			Ticket:          symbol.Ticket,
 		}
 		modelSymbols = append(modelSymbols, modelSymbol)
 	}
@@ -495,6 +498,7 @@ func (w *Writer) WriteASTNodesWithTransaction(ctx context.Context, nodes []schem
 			EndByte:    node.Span.EndByte,
 			Text:       node.Text,
 			Attributes: node.Attributes,
+			Ticket:     node.Ticket,
 		}
 		modelNodes = append(modelNodes, modelNode)
 	}
@@ -95,7 +95,10 @@ func (w *JSONWriter) writeStreaming(output *schema.ParseOutput) error {
 	return nil
 }
 
-// writeFilesStreaming writes files array in streaming fashion
+// writeFilesStreaming writes files array in streaming fashion, streaming
+// each file's Nodes one at a time (see writeFileStreaming) instead of
+// marshaling the whole file - and its potentially huge Nodes slice - in one
+// allocation.
 func (w *JSONWriter) writeFilesStreaming(files []schema.File) error {
 	indent := ""
 	if w.indent {
@@ -107,16 +110,11 @@ func (w *JSONWriter) writeFilesStreaming(files []schema.File) error {
 	}
 
 	for i, file := range files {
-		fileJSON, err := json.MarshalIndent(file, indent+"  ", "  ")
-		if err != nil {
-			return fmt.Errorf("failed to marshal file: %w", err)
-		}
-
 		if _, err := w.writer.Write([]byte(indent + "  ")); err != nil {
 			return err
 		}
-		if _, err := w.writer.Write(fileJSON); err != nil {
-			return err
+		if err := w.writeFileStreaming(file); err != nil {
+			return fmt.Errorf("failed to write file %s: %w", file.FileID, err)
 		}
 
 		// Add comma if not last element
@@ -137,6 +135,63 @@ func (w *JSONWriter) writeFilesStreaming(files []schema.File) error {
 	return nil
 }
 
+// writeFileStreaming writes a single file object, marshaling its fixed
+// fields up front but its Nodes array element-by-element, so a file with a
+// huge AST doesn't need its full node slice materialized as one JSON value.
+func (w *JSONWriter) writeFileStreaming(file schema.File) error {
+	head := struct {
+		FileID   string `json:"file_id"`
+		Path     string `json:"path"`
+		Language string `json:"language"`
+		Size     int64  `json:"size"`
+		Checksum string `json:"checksum"`
+	}{file.FileID, file.Path, file.Language, file.Size, file.Checksum}
+
+	headJSON, err := json.Marshal(head)
+	if err != nil {
+		return fmt.Errorf("failed to marshal file fields: %w", err)
+	}
+	// Drop the closing brace so "nodes" and "symbols" can be appended.
+	if _, err := w.writer.Write(headJSON[:len(headJSON)-1]); err != nil {
+		return err
+	}
+
+	if _, err := w.writer.Write([]byte(`,"nodes":[`)); err != nil {
+		return err
+	}
+	for i, node := range file.Nodes {
+		nodeJSON, err := json.Marshal(node)
+		if err != nil {
+			return fmt.Errorf("failed to marshal node %s: %w", node.NodeID, err)
+		}
+		if i > 0 {
+			if _, err := w.writer.Write([]byte(",")); err != nil {
+				return err
+			}
+		}
+		if _, err := w.writer.Write(nodeJSON); err != nil {
+			return err
+		}
+	}
+	if _, err := w.writer.Write([]byte("]")); err != nil {
+		return err
+	}
+
+	symbolsJSON, err := json.Marshal(file.Symbols)
+	if err != nil {
+		return fmt.Errorf("failed to marshal symbols: %w", err)
+	}
+	if _, err := w.writer.Write([]byte(`,"symbols":`)); err != nil {
+		return err
+	}
+	if _, err := w.writer.Write(symbolsJSON); err != nil {
+		return err
+	}
+
+	_, err = w.writer.Write([]byte("}"))
+	return err
+}
+
 // writeRelationshipsStreaming writes relationships array in streaming fashion
 func (w *JSONWriter) writeRelationshipsStreaming(relationships []schema.DependencyEdge) error {
 	indent := ""
@@ -452,6 +452,47 @@ func TestWriteFilesStreaming_MultipleFiles(t *testing.T) {
 	}
 }
 
+func TestWriteFilesStreaming_StreamsNodesIndividually(t *testing.T) {
+	file := schema.File{
+		FileID:   "file1",
+		Path:     "test.go",
+		Language: "go",
+		Nodes: []schema.ASTNode{
+			{NodeID: "node1", FileID: "file1", Type: "function_declaration"},
+			{NodeID: "node2", FileID: "file1", Type: "identifier", ParentID: "node1"},
+		},
+	}
+
+	var buf bytes.Buffer
+	writer := NewStreamingJSONWriter(&buf, true)
+
+	if err := writer.writeFilesStreaming([]schema.File{file}); err != nil {
+		t.Fatalf("writeFilesStreaming failed: %v", err)
+	}
+
+	var parsed []schema.File
+	// writeFilesStreaming emits "files": [...] as a bare JSON fragment, so
+	// wrap it into an object before unmarshaling.
+	wrapped := "{" + strings.TrimSuffix(strings.TrimSpace(buf.String()), ",") + "}"
+	var obj struct {
+		Files []schema.File `json:"files"`
+	}
+	if err := json.Unmarshal([]byte(wrapped), &obj); err != nil {
+		t.Fatalf("failed to parse streamed files: %v", err)
+	}
+	parsed = obj.Files
+
+	if len(parsed) != 1 {
+		t.Fatalf("expected 1 file, got %d", len(parsed))
+	}
+	if len(parsed[0].Nodes) != 2 {
+		t.Fatalf("expected 2 nodes, got %d", len(parsed[0].Nodes))
+	}
+	if parsed[0].Nodes[1].ParentID != "node1" {
+		t.Errorf("expected second node's parent to survive streaming, got %q", parsed[0].Nodes[1].ParentID)
+	}
+}
+
 func TestWriteRelationshipsStreaming_MultipleRelationships(t *testing.T) {
 	relationships := []schema.DependencyEdge{
 		{EdgeID: "edge1", SourceID: "sym1", TargetID: "sym2", EdgeType: schema.EdgeCall},
@@ -0,0 +1,74 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/yourtionguo/CodeAtlas/internal/parser/testutil"
+)
+
+// coverageArtifactPath is where the cross-language coverage matrix is
+// written so `codeatlas test-matrix` can display it. It is relative to this
+// package's directory, matching the fixture paths below.
+const coverageArtifactPath = "../../tests/fixtures/.coverage/cross_language_matrix.json"
+
+// crossLanguageFixtures is the matrix of "calls-to" fixtures the harness
+// grows coverage over. Adding a new language pair means adding an entry
+// here, not a new hand-written test function.
+var crossLanguageFixtures = []testutil.CrossLanguageFixture{
+	{
+		SourceLanguage: "swift",
+		TargetLanguage: "objc",
+		FixturePath:    "../../tests/fixtures/swift/swift_calls_objc.swift",
+		NewParser: func(ts *TreeSitterParser) testutil.LanguageParser {
+			return NewSwiftParser(ts)
+		},
+		ExpectedSymbols: []string{"SwiftViewController", "BridgedClass"},
+		ExpectedEdges: []testutil.ExpectedEdge{
+			{Type: "import", Target: "Foundation"},
+			{Type: "extends", Target: "NSObject"},
+		},
+	},
+	{
+		SourceLanguage: "kotlin",
+		TargetLanguage: "java",
+		FixturePath:    "../../tests/fixtures/kotlin/kotlin_calls_java.kt",
+		NewParser: func(ts *TreeSitterParser) testutil.LanguageParser {
+			return NewKotlinParser(ts)
+		},
+	},
+	{
+		SourceLanguage: "typescript",
+		TargetLanguage: "javascript",
+		FixturePath:    "../../tests/fixtures/js/typescript_calls_js.ts",
+		NewParser: func(ts *TreeSitterParser) testutil.LanguageParser {
+			return NewJSParser(ts)
+		},
+	},
+	{
+		SourceLanguage: "python",
+		TargetLanguage: "c",
+		FixturePath:    "../../tests/fixtures/python/python_calls_c.py",
+		NewParser: func(ts *TreeSitterParser) testutil.LanguageParser {
+			return NewPythonParser(ts)
+		},
+	},
+	{
+		SourceLanguage: "go",
+		TargetLanguage: "c",
+		FixturePath:    "../../tests/fixtures/go/go_calls_c.go",
+		NewParser: func(ts *TreeSitterParser) testutil.LanguageParser {
+			return NewGoParser(ts)
+		},
+	},
+}
+
+// TestCrossLanguageMatrix exercises every declared (source, target) language
+// pair through the shared testutil harness and persists a coverage artifact
+// for `codeatlas test-matrix`.
+func TestCrossLanguageMatrix(t *testing.T) {
+	coverage := testutil.RunMatrix(t, crossLanguageFixtures)
+
+	if err := testutil.WriteCoverageArtifact(coverageArtifactPath, coverage); err != nil {
+		t.Fatalf("failed to write coverage artifact: %v", err)
+	}
+}
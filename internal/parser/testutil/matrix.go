@@ -0,0 +1,150 @@
+// Package testutil provides a table-driven harness for the parser package's
+// cross-language "calls-to" fixtures (e.g. Swift calling Objective-C, Kotlin
+// calling Java). It replaces hand-written, near-identical test functions per
+// language pair with a single matrix of expectations run through t.Run.
+package testutil
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yourtionguo/CodeAtlas/internal/parser"
+)
+
+// LanguageParser is the subset of each language parser's API the harness
+// needs to parse a fixture file.
+type LanguageParser interface {
+	Parse(file parser.ScannedFile) (*parser.ParsedFile, error)
+}
+
+// ParserFactory builds the LanguageParser used to parse a fixture's source
+// file, e.g. func(ts *parser.TreeSitterParser) testutil.LanguageParser {
+// return parser.NewSwiftParser(ts) }.
+type ParserFactory func(ts *parser.TreeSitterParser) LanguageParser
+
+// ExpectedEdge describes a dependency edge a fixture must produce.
+type ExpectedEdge struct {
+	Type   string // edge kind, e.g. "import", "call", "extends", "conforms"
+	Target string
+}
+
+// CrossLanguageFixture describes one (source language, target language) pair
+// to exercise through RunMatrix.
+type CrossLanguageFixture struct {
+	// Name is the subtest name; defaults to "<SourceLanguage>_calls_<TargetLanguage>".
+	Name string
+
+	SourceLanguage string // language tag passed to ScannedFile, e.g. "swift"
+	TargetLanguage string // the language being called into, e.g. "objc"
+	FixturePath    string // path relative to the parser package, e.g. "../../tests/fixtures/swift/swift_calls_objc.swift"
+	NewParser      ParserFactory
+
+	ExpectedSymbols []string
+	ExpectedEdges   []ExpectedEdge
+}
+
+// PairCoverage records whether a fixture's file exists and whether its
+// assertions passed, for one (source, target) language pair.
+type PairCoverage struct {
+	SourceLanguage string `json:"source_language"`
+	TargetLanguage string `json:"target_language"`
+	FixturePath    string `json:"fixture_path"`
+	FixtureExists  bool   `json:"fixture_exists"`
+	Passed         bool   `json:"passed"`
+}
+
+// RunMatrix runs one subtest per fixture via t.Run, asserting that every
+// expected symbol and dependency edge is present in the parsed output.
+// Fixtures whose file is missing are skipped, matching the historical
+// behavior of the hand-written per-pair tests. It returns per-pair coverage
+// so callers can persist it with WriteCoverageArtifact.
+func RunMatrix(t *testing.T, fixtures []CrossLanguageFixture) []PairCoverage {
+	coverage := make([]PairCoverage, 0, len(fixtures))
+
+	for _, fx := range fixtures {
+		fx := fx
+		name := fx.Name
+		if name == "" {
+			name = fx.SourceLanguage + "_calls_" + fx.TargetLanguage
+		}
+
+		entry := PairCoverage{
+			SourceLanguage: fx.SourceLanguage,
+			TargetLanguage: fx.TargetLanguage,
+			FixturePath:    fx.FixturePath,
+		}
+
+		t.Run(name, func(t *testing.T) {
+			if _, err := os.ReadFile(fx.FixturePath); err != nil {
+				t.Skip("fixture file does not exist")
+			}
+			entry.FixtureExists = true
+
+			tsParser, err := parser.NewTreeSitterParser()
+			require.NoError(t, err)
+
+			absPath, err := filepath.Abs(fx.FixturePath)
+			require.NoError(t, err)
+
+			file := parser.ScannedFile{
+				Path:     fx.FixturePath,
+				AbsPath:  absPath,
+				Language: fx.SourceLanguage,
+			}
+
+			parsedFile, err := fx.NewParser(tsParser).Parse(file)
+			require.NoError(t, err)
+			require.NotNil(t, parsedFile)
+
+			for _, want := range fx.ExpectedSymbols {
+				found := false
+				for _, sym := range parsedFile.Symbols {
+					if sym.Name == want {
+						found = true
+						break
+					}
+				}
+				assert.True(t, found, "expected symbol %q", want)
+			}
+
+			for _, want := range fx.ExpectedEdges {
+				found := false
+				for _, dep := range parsedFile.Dependencies {
+					if dep.Type == want.Type && dep.Target == want.Target {
+						found = true
+						break
+					}
+				}
+				assert.True(t, found, "expected %s edge to %q", want.Type, want.Target)
+			}
+
+			entry.Passed = !t.Failed()
+		})
+
+		coverage = append(coverage, entry)
+	}
+
+	return coverage
+}
+
+// WriteCoverageArtifact writes the matrix's coverage as indented JSON to
+// path, creating parent directories as needed. `codeatlas test-matrix` reads
+// this artifact to show contributors which language pairs still lack
+// fixtures or assertions.
+func WriteCoverageArtifact(path string, coverage []PairCoverage) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(coverage, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0o644)
+}
@@ -6,6 +6,7 @@ import (
 	sitter "github.com/smacker/go-tree-sitter"
 	"github.com/yourtionguo/CodeAtlas/internal/parser"
 	"github.com/yourtionguo/CodeAtlas/internal/utils"
+	"github.com/yourtionguo/CodeAtlas/pkg/models"
 )
 
 // SchemaMapper transforms parsed files into the unified schema format
@@ -14,6 +15,9 @@ type SchemaMapper struct {
 	symbolIDMap     map[string]string
 	// Map to track external symbols (module name -> Symbol)
 	externalSymbols map[string]*Symbol
+	// corpus identifies the repository in tickets minted by this mapper;
+	// defaults to empty (an unnamed/local corpus) unless SetCorpus is called
+	corpus string
 }
 
 // NewSchemaMapper creates a new schema mapper
@@ -24,6 +28,12 @@ func NewSchemaMapper() *SchemaMapper {
 	}
 }
 
+// SetCorpus sets the repository name used as the corpus in tickets minted
+// for symbols and AST nodes mapped after this call.
+func (m *SchemaMapper) SetCorpus(corpus string) {
+	m.corpus = corpus
+}
+
 // MapToSchema transforms a ParsedFile into a schema.File
 func (m *SchemaMapper) MapToSchema(parsed *parser.ParsedFile) (*File, []DependencyEdge, error) {
 	// Generate deterministic file ID based on path and checksum
@@ -46,7 +56,7 @@ func (m *SchemaMapper) MapToSchema(parsed *parser.ParsedFile) (*File, []Dependen
 
 	// Map symbols
 	for _, parsedSymbol := range parsed.Symbols {
-		symbol := m.mapSymbol(parsedSymbol, fileID)
+		symbol := m.mapSymbol(parsedSymbol, fileID, parsed.Path, parsed.Language)
 		file.Symbols = append(file.Symbols, symbol)
 
 		// Store symbol ID for dependency resolution
@@ -65,7 +75,7 @@ func (m *SchemaMapper) MapToSchema(parsed *parser.ParsedFile) (*File, []Dependen
 
 	// Map AST nodes if root node exists
 	if parsed.RootNode != nil {
-		astNodes := m.mapASTNodes(parsed.RootNode, fileID, "", parsed.Content)
+		astNodes := m.mapASTNodes(parsed.RootNode, fileID, "", parsed.Content, parsed.Path, parsed.Language)
 		file.Nodes = astNodes
 	}
 
@@ -76,7 +86,7 @@ func (m *SchemaMapper) MapToSchema(parsed *parser.ParsedFile) (*File, []Dependen
 }
 
 // mapSymbol transforms a ParsedSymbol into a schema.Symbol
-func (m *SchemaMapper) mapSymbol(parsed parser.ParsedSymbol, fileID string) Symbol {
+func (m *SchemaMapper) mapSymbol(parsed parser.ParsedSymbol, fileID string, path string, language string) Symbol {
 	// Generate deterministic UUID based on file_id, name, start_line, and start_byte
 	// This ensures the same symbol always gets the same ID across multiple parses
 	symbolKey := fmt.Sprintf("%s:%s:%d:%d", fileID, parsed.Name, parsed.Span.StartLine, parsed.Span.StartByte)
@@ -93,6 +103,13 @@ func (m *SchemaMapper) mapSymbol(parsed parser.ParsedSymbol, fileID string) Symb
 		EndByte:   parsed.Span.EndByte,
 	}
 
+	ticket := models.FormatTicket(models.Ticket{
+		Corpus:    m.corpus,
+		Language:  language,
+		Path:      path,
+		Signature: models.SymbolSignature(string(kind), parsed.Name, parsed.Span.StartLine, parsed.Span.StartByte),
+	})
+
 	symbol := Symbol{
 		SymbolID:  symbolID,
 		FileID:    fileID,
@@ -101,6 +118,7 @@ func (m *SchemaMapper) mapSymbol(parsed parser.ParsedSymbol, fileID string) Symb
 		Signature: parsed.Signature,
 		Span:      span,
 		Docstring: parsed.Docstring,
+		Ticket:    ticket,
 	}
 
 	return symbol
@@ -127,7 +145,7 @@ func (m *SchemaMapper) mapSymbolKind(kind string) SymbolKind {
 }
 
 // mapASTNodes recursively transforms Tree-sitter nodes into schema.ASTNode
-func (m *SchemaMapper) mapASTNodes(node *sitter.Node, fileID string, parentID string, content []byte) []ASTNode {
+func (m *SchemaMapper) mapASTNodes(node *sitter.Node, fileID string, parentID string, content []byte, path string, language string) []ASTNode {
 	if node == nil {
 		return nil
 	}
@@ -151,6 +169,13 @@ func (m *SchemaMapper) mapASTNodes(node *sitter.Node, fileID string, parentID st
 		text = node.Content(content)
 	}
 
+	ticket := models.FormatTicket(models.Ticket{
+		Corpus:    m.corpus,
+		Language:  language,
+		Path:      path,
+		Signature: models.ASTNodeSignature(node.Type(), span.StartLine, span.StartByte, span.EndByte),
+	})
+
 	astNode := ASTNode{
 		NodeID:     nodeID,
 		FileID:     fileID,
@@ -159,6 +184,7 @@ func (m *SchemaMapper) mapASTNodes(node *sitter.Node, fileID string, parentID st
 		ParentID:   parentID,
 		Text:       text,
 		Attributes: make(map[string]string),
+		Ticket:     ticket,
 	}
 
 	// Add node type as attribute
@@ -171,7 +197,7 @@ func (m *SchemaMapper) mapASTNodes(node *sitter.Node, fileID string, parentID st
 	// Recursively process children
 	for i := 0; i < int(node.ChildCount()); i++ {
 		child := node.Child(i)
-		childNodes := m.mapASTNodes(child, fileID, nodeID, content)
+		childNodes := m.mapASTNodes(child, fileID, nodeID, content, path, language)
 		nodes = append(nodes, childNodes...)
 	}
 
@@ -286,6 +312,11 @@ func (m *SchemaMapper) createExternalSymbol(moduleName string) Symbol {
 			StartByte: 0,
 			EndByte:   0,
 		},
+		Ticket: models.FormatTicket(models.Ticket{
+			Corpus:    m.corpus,
+			Path:      ExternalFilePath,
+			Signature: models.SymbolSignature(string(SymbolExternal), moduleName, 1, 0),
+		}),
 	}
 }
 
@@ -7,6 +7,7 @@ import (
 	"github.com/smacker/go-tree-sitter/golang"
 	"github.com/yourtionguo/CodeAtlas/internal/parser"
 	"github.com/yourtionguo/CodeAtlas/internal/utils"
+	"github.com/yourtionguo/CodeAtlas/pkg/models"
 )
 
 func TestNewSchemaMapper(t *testing.T) {
@@ -812,3 +813,56 @@ func hello() {
 		t.Error("Call edge target ID not found in file symbols")
 	}
 }
+
+func TestMapToSchemaAssignsTickets(t *testing.T) {
+	mapper := NewSchemaMapper()
+	mapper.SetCorpus("yourtion/CodeAtlas")
+
+	parsedFile := &parser.ParsedFile{
+		Path:     "pkg/foo.go",
+		Language: "go",
+		Content:  []byte(`package main`),
+		Symbols: []parser.ParsedSymbol{
+			{
+				Name:      "Foo",
+				Kind:      "function",
+				Signature: "func Foo()",
+				Span: parser.ParsedSpan{
+					StartLine: 1,
+					EndLine:   1,
+					StartByte: 0,
+					EndByte:   12,
+				},
+			},
+		},
+	}
+
+	file, _, err := mapper.MapToSchema(parsedFile)
+	if err != nil {
+		t.Fatalf("MapToSchema failed: %v", err)
+	}
+
+	symbol := file.Symbols[0]
+	if symbol.Ticket == "" {
+		t.Fatal("expected symbol to have a ticket assigned")
+	}
+
+	ticket, err := models.ParseTicket(symbol.Ticket)
+	if err != nil {
+		t.Fatalf("ParseTicket(%q) returned error: %v", symbol.Ticket, err)
+	}
+	if ticket.Corpus != "yourtion/CodeAtlas" || ticket.Language != "go" || ticket.Path != "pkg/foo.go" {
+		t.Errorf("unexpected ticket fields: %+v", ticket)
+	}
+
+	// Re-mapping the same file should produce the same ticket even though
+	// SymbolID is a fresh deterministic UUID each time; the ticket depends
+	// on symbol kind/name/line, not on the UUID.
+	file2, _, err := mapper.MapToSchema(parsedFile)
+	if err != nil {
+		t.Fatalf("MapToSchema failed: %v", err)
+	}
+	if file2.Symbols[0].Ticket != symbol.Ticket {
+		t.Errorf("expected ticket to be stable across re-parses, got %q and %q", symbol.Ticket, file2.Symbols[0].Ticket)
+	}
+}
@@ -6,6 +6,7 @@ import "time"
 type ParseOutput struct {
 	Files         []File           `json:"files"`
 	Relationships []DependencyEdge `json:"relationships"`
+	Analyses      []AnalysisResult `json:"analyses,omitempty"`
 	Metadata      ParseMetadata    `json:"metadata"`
 }
 
@@ -60,6 +61,7 @@ type Symbol struct {
 	Span            Span       `json:"span"`
 	Docstring       string     `json:"docstring,omitempty"`
 	SemanticSummary string     `json:"semantic_summary,omitempty"`
+	Ticket          string     `json:"ticket,omitempty"`
 }
 
 // SymbolKind represents the type of symbol
@@ -90,6 +92,7 @@ type ASTNode struct {
 	ParentID   string            `json:"parent_id,omitempty"`
 	Text       string            `json:"text,omitempty"`
 	Attributes map[string]string `json:"attributes,omitempty"`
+	Ticket     string            `json:"ticket,omitempty"`
 }
 
 // Span represents the location of a code element
@@ -100,6 +103,21 @@ type Span struct {
 	EndByte   int `json:"end_byte"`
 }
 
+// AnalysisResult is a static-analysis finding an external linter (semgrep,
+// staticcheck, a custom LLM-driven rule, ...) attaches to an AST node so it
+// travels with the parse output and becomes queryable via the same
+// knowledge graph as the AST it was found in.
+type AnalysisResult struct {
+	RuleID    string            `json:"rule_id"`
+	Category  string            `json:"category"`
+	Severity  string            `json:"severity"`
+	Message   string            `json:"message"`
+	NodeID    string            `json:"node_id"`
+	FileID    string            `json:"file_id"`
+	Span      Span              `json:"span"`
+	Variables map[string]string `json:"variables,omitempty"`
+}
+
 // DependencyEdge represents relationships between symbols
 type DependencyEdge struct {
 	EdgeID       string   `json:"edge_id"`
@@ -0,0 +1,236 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// AnalysisIssue is a rule definition a static-analysis tool can report
+// findings against, e.g. a semgrep rule or a staticcheck check.
+type AnalysisIssue struct {
+	IssueID   string    `json:"issue_id" db:"issue_id"`
+	RuleID    string    `json:"rule_id" db:"rule_id"`
+	Category  string    `json:"category" db:"category"`
+	Severity  string    `json:"severity" db:"severity"`
+	Message   string    `json:"message" db:"message"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// AnalysisIncident is one occurrence of an AnalysisIssue at a specific AST
+// node, linking a static-analysis finding into the knowledge graph so it can
+// be queried alongside the code it was found in.
+type AnalysisIncident struct {
+	IncidentID string            `json:"incident_id" db:"incident_id"`
+	IssueID    string            `json:"issue_id" db:"issue_id"`
+	NodeID     string            `json:"node_id" db:"node_id"`
+	FileID     string            `json:"file_id" db:"file_id"`
+	Line       int               `json:"line" db:"line"`
+	Column     int               `json:"column" db:"column_number"`
+	Variables  map[string]string `json:"variables,omitempty" db:"variables_json"`
+	CreatedAt  time.Time         `json:"created_at" db:"created_at"`
+}
+
+// IncidentWithIssue bundles an incident with the issue it's reporting, the
+// shape GetIncidentsByFile/GetIncidentsByNode return since callers almost
+// always need both (e.g. to render "rule X fired at line Y").
+type IncidentWithIssue struct {
+	Incident *AnalysisIncident `json:"incident"`
+	Issue    *AnalysisIssue    `json:"issue"`
+}
+
+// AnalysisRepository handles CRUD operations for static-analysis issues and
+// the incidents that attach them to AST nodes.
+type AnalysisRepository struct {
+	db *DB
+}
+
+// NewAnalysisRepository creates a new analysis repository
+func NewAnalysisRepository(db *DB) *AnalysisRepository {
+	return &AnalysisRepository{db: db}
+}
+
+// AttachIncident records issue as having fired at nodeID, creating the
+// AnalysisIssue row if rule_id isn't already known and inserting a new
+// AnalysisIncident pointing at it. It returns the incident as persisted
+// (with IncidentID and CreatedAt populated).
+func (r *AnalysisRepository) AttachIncident(ctx context.Context, nodeID, fileID string, issue *AnalysisIssue, line, column int, variables map[string]string) (*AnalysisIncident, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	issueID, err := r.findOrCreateIssueTx(ctx, tx, issue)
+	if err != nil {
+		return nil, err
+	}
+
+	var variablesJSON []byte
+	if variables != nil {
+		variablesJSON, err = json.Marshal(variables)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal incident variables: %w", err)
+		}
+	}
+
+	incident := &AnalysisIncident{
+		IssueID:   issueID,
+		NodeID:    nodeID,
+		FileID:    fileID,
+		Line:      line,
+		Column:    column,
+		Variables: variables,
+		CreatedAt: time.Now(),
+	}
+
+	query := `
+		INSERT INTO analysis_incidents (incident_id, issue_id, node_id, file_id, line, column_number, variables_json, created_at)
+		VALUES (gen_random_uuid(), $1, $2, $3, $4, $5, $6, $7)
+		RETURNING incident_id
+	`
+	err = tx.QueryRowContext(ctx, query,
+		incident.IssueID, incident.NodeID, incident.FileID,
+		incident.Line, incident.Column, variablesJSON, incident.CreatedAt).Scan(&incident.IncidentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert analysis incident: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return incident, nil
+}
+
+// findOrCreateIssueTx inserts issue if rule_id isn't already known, or
+// no-ops and falls back to a lookup if a concurrent AttachIncident call for
+// the same rule_id won the race, returning the issue_id either way. rule_id
+// is UNIQUE, so this can't race into duplicate issue rows the way a
+// SELECT-then-INSERT would.
+func (r *AnalysisRepository) findOrCreateIssueTx(ctx context.Context, tx *sql.Tx, issue *AnalysisIssue) (string, error) {
+	issue.CreatedAt = time.Now()
+	var issueID string
+	err := tx.QueryRowContext(ctx, `
+		INSERT INTO analysis_issues (issue_id, rule_id, category, severity, message, created_at)
+		VALUES (gen_random_uuid(), $1, $2, $3, $4, $5)
+		ON CONFLICT (rule_id) DO NOTHING
+		RETURNING issue_id
+	`, issue.RuleID, issue.Category, issue.Severity, issue.Message, issue.CreatedAt).Scan(&issueID)
+	if err == nil {
+		return issueID, nil
+	}
+	if err != sql.ErrNoRows {
+		return "", fmt.Errorf("failed to insert analysis issue %s: %w", issue.RuleID, err)
+	}
+
+	// ON CONFLICT DO NOTHING returned no row, so another transaction already
+	// holds rule_id; re-select its issue_id.
+	err = tx.QueryRowContext(ctx,
+		`SELECT issue_id FROM analysis_issues WHERE rule_id = $1`, issue.RuleID).Scan(&issueID)
+	if err != nil {
+		return "", fmt.Errorf("failed to look up analysis issue %s: %w", issue.RuleID, err)
+	}
+	return issueID, nil
+}
+
+// GetIncidentsByFile returns every incident recorded against fileID, each
+// paired with the issue it reports, ordered by line then column.
+func (r *AnalysisRepository) GetIncidentsByFile(ctx context.Context, fileID string) ([]*IncidentWithIssue, error) {
+	query := `
+		SELECT i.incident_id, i.issue_id, i.node_id, i.file_id, i.line, i.column_number, i.variables_json, i.created_at,
+			s.issue_id, s.rule_id, s.category, s.severity, s.message, s.created_at
+		FROM analysis_incidents i
+		JOIN analysis_issues s ON s.issue_id = i.issue_id
+		WHERE i.file_id = $1
+		ORDER BY i.line, i.column_number
+	`
+	rows, err := r.db.QueryContext(ctx, query, fileID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query incidents for file %s: %w", fileID, err)
+	}
+	defer rows.Close()
+
+	return scanIncidentsWithIssue(rows)
+}
+
+// GetIncidentsByNode returns every incident recorded against nodeID, each
+// paired with the issue it reports.
+func (r *AnalysisRepository) GetIncidentsByNode(ctx context.Context, nodeID string) ([]*IncidentWithIssue, error) {
+	query := `
+		SELECT i.incident_id, i.issue_id, i.node_id, i.file_id, i.line, i.column_number, i.variables_json, i.created_at,
+			s.issue_id, s.rule_id, s.category, s.severity, s.message, s.created_at
+		FROM analysis_incidents i
+		JOIN analysis_issues s ON s.issue_id = i.issue_id
+		WHERE i.node_id = $1
+		ORDER BY i.created_at
+	`
+	rows, err := r.db.QueryContext(ctx, query, nodeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query incidents for node %s: %w", nodeID, err)
+	}
+	defer rows.Close()
+
+	return scanIncidentsWithIssue(rows)
+}
+
+func scanIncidentsWithIssue(rows *sql.Rows) ([]*IncidentWithIssue, error) {
+	var results []*IncidentWithIssue
+	for rows.Next() {
+		var incident AnalysisIncident
+		var issue AnalysisIssue
+		var variablesJSON []byte
+
+		err := rows.Scan(
+			&incident.IncidentID, &incident.IssueID, &incident.NodeID, &incident.FileID,
+			&incident.Line, &incident.Column, &variablesJSON, &incident.CreatedAt,
+			&issue.IssueID, &issue.RuleID, &issue.Category, &issue.Severity, &issue.Message, &issue.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan analysis incident: %w", err)
+		}
+
+		if variablesJSON != nil {
+			if err := json.Unmarshal(variablesJSON, &incident.Variables); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal incident variables: %w", err)
+			}
+		}
+
+		results = append(results, &IncidentWithIssue{Incident: &incident, Issue: &issue})
+	}
+	return results, rows.Err()
+}
+
+// IssueCountsByRule returns, for fileID, how many incidents each rule_id
+// triggered, enabling "show every call site that triggers rule X" rollups
+// across a file without loading every incident.
+func (r *AnalysisRepository) IssueCountsByRule(ctx context.Context, fileID string) (map[string]int64, error) {
+	query := `
+		SELECT s.rule_id, COUNT(*)
+		FROM analysis_incidents i
+		JOIN analysis_issues s ON s.issue_id = i.issue_id
+		WHERE i.file_id = $1
+		GROUP BY s.rule_id
+	`
+	rows, err := r.db.QueryContext(ctx, query, fileID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count issues by rule for file %s: %w", fileID, err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int64)
+	for rows.Next() {
+		var ruleID string
+		var count int64
+		if err := rows.Scan(&ruleID, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan issue count: %w", err)
+		}
+		counts[ruleID] = count
+	}
+	return counts, rows.Err()
+}
@@ -0,0 +1,123 @@
+package models
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestAnalysisRepository_AttachIncidentAndQuery(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	testDB := SetupTestDB(t)
+	defer testDB.TeardownTestDB(t)
+
+	ctx := context.Background()
+	repoRepo := NewRepositoryRepository(testDB.DB)
+	fileRepo := NewFileRepository(testDB.DB)
+	nodeRepo := NewASTNodeRepository(testDB.DB)
+	analysisRepo := NewAnalysisRepository(testDB.DB)
+
+	repo := &Repository{RepoID: uuid.New().String(), Name: "test-repo-analysis"}
+	if err := repoRepo.Create(ctx, repo); err != nil {
+		t.Fatalf("Failed to create repository: %v", err)
+	}
+	defer repoRepo.Delete(ctx, repo.RepoID)
+
+	file := &File{
+		FileID:   uuid.New().String(),
+		RepoID:   repo.RepoID,
+		Path:     "src/handler.go",
+		Language: "go",
+		Size:     512,
+		Checksum: "analysis123",
+	}
+	if err := fileRepo.Create(ctx, file); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	defer fileRepo.Delete(ctx, file.FileID)
+
+	node := &ASTNode{
+		NodeID:    uuid.New().String(),
+		FileID:    file.FileID,
+		Type:      "call_expression",
+		StartLine: 12,
+		EndLine:   12,
+		StartByte: 100,
+		EndByte:   120,
+		Text:      "db.Query(userInput)",
+	}
+	if err := nodeRepo.Create(ctx, node); err != nil {
+		t.Fatalf("Failed to create AST node: %v", err)
+	}
+	defer nodeRepo.Delete(ctx, node.NodeID)
+
+	issue := &AnalysisIssue{
+		RuleID:   "sql-injection",
+		Category: "security",
+		Severity: "high",
+		Message:  "possible SQL injection via unsanitized input",
+	}
+
+	incident, err := analysisRepo.AttachIncident(ctx, node.NodeID, file.FileID, issue, 12, 5, map[string]string{"arg": "userInput"})
+	if err != nil {
+		t.Fatalf("Failed to attach incident: %v", err)
+	}
+	if incident.IncidentID == "" {
+		t.Fatal("Expected incident to have an ID")
+	}
+
+	byNode, err := analysisRepo.GetIncidentsByNode(ctx, node.NodeID)
+	if err != nil {
+		t.Fatalf("Failed to get incidents by node: %v", err)
+	}
+	if len(byNode) != 1 {
+		t.Fatalf("Expected 1 incident for node, got %d", len(byNode))
+	}
+	if byNode[0].Issue.RuleID != "sql-injection" {
+		t.Errorf("Expected rule_id sql-injection, got %s", byNode[0].Issue.RuleID)
+	}
+	if byNode[0].Incident.Variables["arg"] != "userInput" {
+		t.Errorf("Expected variable arg=userInput, got %+v", byNode[0].Incident.Variables)
+	}
+
+	byFile, err := analysisRepo.GetIncidentsByFile(ctx, file.FileID)
+	if err != nil {
+		t.Fatalf("Failed to get incidents by file: %v", err)
+	}
+	if len(byFile) != 1 {
+		t.Fatalf("Expected 1 incident for file, got %d", len(byFile))
+	}
+
+	// Attaching a second incident for the same rule should reuse the issue
+	// row rather than creating a duplicate.
+	node2 := &ASTNode{
+		NodeID:    uuid.New().String(),
+		FileID:    file.FileID,
+		Type:      "call_expression",
+		StartLine: 30,
+		EndLine:   30,
+		StartByte: 300,
+		EndByte:   320,
+		Text:      "db.Query(otherInput)",
+	}
+	if err := nodeRepo.Create(ctx, node2); err != nil {
+		t.Fatalf("Failed to create second AST node: %v", err)
+	}
+	defer nodeRepo.Delete(ctx, node2.NodeID)
+
+	if _, err := analysisRepo.AttachIncident(ctx, node2.NodeID, file.FileID, issue, 30, 5, nil); err != nil {
+		t.Fatalf("Failed to attach second incident: %v", err)
+	}
+
+	counts, err := analysisRepo.IssueCountsByRule(ctx, file.FileID)
+	if err != nil {
+		t.Fatalf("Failed to count issues by rule: %v", err)
+	}
+	if counts["sql-injection"] != 2 {
+		t.Errorf("Expected 2 incidents for rule sql-injection, got %d", counts["sql-injection"])
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"time"
+
+	"github.com/lib/pq"
 )
 
 // ASTNode represents an AST node entity in the knowledge graph
@@ -20,7 +22,13 @@ type ASTNode struct {
 	EndByte    int               `json:"end_byte" db:"end_byte"`
 	Text       string            `json:"text" db:"text"`
 	Attributes map[string]string `json:"attributes" db:"attributes"`
-	CreatedAt  time.Time         `json:"created_at" db:"created_at"`
+	Ticket     string            `json:"ticket,omitempty" db:"ticket"`
+	// SubtreeHash is the bottom-up hash of this node's subtree (type + sorted
+	// child hashes + normalized text), used by Diff to match identical
+	// subtrees across two parses in O(n). It is only populated by
+	// BatchCreate, which sees the whole tree at once; nil otherwise.
+	SubtreeHash *string   `json:"subtree_hash,omitempty" db:"subtree_hash"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
 }
 
 // ASTNodeRepository handles CRUD operations for AST nodes
@@ -33,18 +41,29 @@ func NewASTNodeRepository(db *DB) *ASTNodeRepository {
 	return &ASTNodeRepository{db: db}
 }
 
-// Create inserts a new AST node record
+// Create inserts a new AST node record and seeds its ast_node_closure rows
+// (its own depth-0 row plus one row per ancestor, copied from its parent)
+// in the same transaction.
 func (r *ASTNodeRepository) Create(ctx context.Context, node *ASTNode) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
 	query := `
 		INSERT INTO ast_nodes (node_id, file_id, type, parent_id, start_line, end_line,
-			start_byte, end_byte, text, attributes, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+			start_byte, end_byte, text, attributes, ticket, subtree_hash, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
 	`
 	node.CreatedAt = time.Now()
 
 	// Convert attributes to JSON
 	var attributesJSON []byte
-	var err error
 	if node.Attributes != nil {
 		attributesJSON, err = json.Marshal(node.Attributes)
 		if err != nil {
@@ -52,18 +71,36 @@ func (r *ASTNodeRepository) Create(ctx context.Context, node *ASTNode) error {
 		}
 	}
 
-	_, err = r.db.ExecContext(ctx, query,
+	if _, err = tx.ExecContext(ctx, query,
 		node.NodeID, node.FileID, node.Type, node.ParentID,
 		node.StartLine, node.EndLine, node.StartByte, node.EndByte,
-		node.Text, attributesJSON, node.CreatedAt)
-	return err
+		node.Text, attributesJSON, node.Ticket, node.SubtreeHash, node.CreatedAt); err != nil {
+		return err
+	}
+
+	if _, err = tx.ExecContext(ctx, `
+		INSERT INTO ast_node_closure (ancestor_id, descendant_id, depth)
+		VALUES ($1, $1, 0)
+		ON CONFLICT (ancestor_id, descendant_id) DO NOTHING
+	`, node.NodeID); err != nil {
+		return fmt.Errorf("failed to insert self closure row: %w", err)
+	}
+
+	if err = moveClosureSubtreeTx(ctx, tx, node.NodeID, node.ParentID); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
 }
 
 // GetByID retrieves an AST node by its ID
 func (r *ASTNodeRepository) GetByID(ctx context.Context, nodeID string) (*ASTNode, error) {
 	query := `
 		SELECT node_id, file_id, type, parent_id, start_line, end_line,
-			start_byte, end_byte, text, attributes, created_at
+			start_byte, end_byte, text, attributes, ticket, subtree_hash, created_at
 		FROM ast_nodes WHERE node_id = $1
 	`
 	var node ASTNode
@@ -71,7 +108,7 @@ func (r *ASTNodeRepository) GetByID(ctx context.Context, nodeID string) (*ASTNod
 	err := r.db.QueryRowContext(ctx, query, nodeID).Scan(
 		&node.NodeID, &node.FileID, &node.Type, &node.ParentID,
 		&node.StartLine, &node.EndLine, &node.StartByte, &node.EndByte,
-		&node.Text, &attributesJSON, &node.CreatedAt)
+		&node.Text, &attributesJSON, &node.Ticket, &node.SubtreeHash, &node.CreatedAt)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
@@ -94,7 +131,7 @@ func (r *ASTNodeRepository) GetByID(ctx context.Context, nodeID string) (*ASTNod
 func (r *ASTNodeRepository) GetByFileID(ctx context.Context, fileID string) ([]*ASTNode, error) {
 	query := `
 		SELECT node_id, file_id, type, parent_id, start_line, end_line,
-			start_byte, end_byte, text, attributes, created_at
+			start_byte, end_byte, text, attributes, ticket, subtree_hash, created_at
 		FROM ast_nodes WHERE file_id = $1 ORDER BY start_line, start_byte
 	`
 	rows, err := r.db.QueryContext(ctx, query, fileID)
@@ -110,7 +147,7 @@ func (r *ASTNodeRepository) GetByFileID(ctx context.Context, fileID string) ([]*
 		err := rows.Scan(
 			&node.NodeID, &node.FileID, &node.Type, &node.ParentID,
 			&node.StartLine, &node.EndLine, &node.StartByte, &node.EndByte,
-			&node.Text, &attributesJSON, &node.CreatedAt)
+			&node.Text, &attributesJSON, &node.Ticket, &node.SubtreeHash, &node.CreatedAt)
 		if err != nil {
 			return nil, err
 		}
@@ -132,7 +169,7 @@ func (r *ASTNodeRepository) GetByFileID(ctx context.Context, fileID string) ([]*
 func (r *ASTNodeRepository) GetByParentID(ctx context.Context, parentID string) ([]*ASTNode, error) {
 	query := `
 		SELECT node_id, file_id, type, parent_id, start_line, end_line,
-			start_byte, end_byte, text, attributes, created_at
+			start_byte, end_byte, text, attributes, ticket, subtree_hash, created_at
 		FROM ast_nodes WHERE parent_id = $1 ORDER BY start_line, start_byte
 	`
 	rows, err := r.db.QueryContext(ctx, query, parentID)
@@ -148,7 +185,7 @@ func (r *ASTNodeRepository) GetByParentID(ctx context.Context, parentID string)
 		err := rows.Scan(
 			&node.NodeID, &node.FileID, &node.Type, &node.ParentID,
 			&node.StartLine, &node.EndLine, &node.StartByte, &node.EndByte,
-			&node.Text, &attributesJSON, &node.CreatedAt)
+			&node.Text, &attributesJSON, &node.Ticket, &node.SubtreeHash, &node.CreatedAt)
 		if err != nil {
 			return nil, err
 		}
@@ -170,7 +207,7 @@ func (r *ASTNodeRepository) GetByParentID(ctx context.Context, parentID string)
 func (r *ASTNodeRepository) GetRootNodes(ctx context.Context, fileID string) ([]*ASTNode, error) {
 	query := `
 		SELECT node_id, file_id, type, parent_id, start_line, end_line,
-			start_byte, end_byte, text, attributes, created_at
+			start_byte, end_byte, text, attributes, ticket, subtree_hash, created_at
 		FROM ast_nodes WHERE file_id = $1 AND parent_id IS NULL 
 		ORDER BY start_line, start_byte
 	`
@@ -187,7 +224,7 @@ func (r *ASTNodeRepository) GetRootNodes(ctx context.Context, fileID string) ([]
 		err := rows.Scan(
 			&node.NodeID, &node.FileID, &node.Type, &node.ParentID,
 			&node.StartLine, &node.EndLine, &node.StartByte, &node.EndByte,
-			&node.Text, &attributesJSON, &node.CreatedAt)
+			&node.Text, &attributesJSON, &node.Ticket, &node.SubtreeHash, &node.CreatedAt)
 		if err != nil {
 			return nil, err
 		}
@@ -209,7 +246,7 @@ func (r *ASTNodeRepository) GetRootNodes(ctx context.Context, fileID string) ([]
 func (r *ASTNodeRepository) GetByType(ctx context.Context, fileID, nodeType string) ([]*ASTNode, error) {
 	query := `
 		SELECT node_id, file_id, type, parent_id, start_line, end_line,
-			start_byte, end_byte, text, attributes, created_at
+			start_byte, end_byte, text, attributes, ticket, subtree_hash, created_at
 		FROM ast_nodes WHERE file_id = $1 AND type = $2 
 		ORDER BY start_line, start_byte
 	`
@@ -226,7 +263,7 @@ func (r *ASTNodeRepository) GetByType(ctx context.Context, fileID, nodeType stri
 		err := rows.Scan(
 			&node.NodeID, &node.FileID, &node.Type, &node.ParentID,
 			&node.StartLine, &node.EndLine, &node.StartByte, &node.EndByte,
-			&node.Text, &attributesJSON, &node.CreatedAt)
+			&node.Text, &attributesJSON, &node.Ticket, &node.SubtreeHash, &node.CreatedAt)
 		if err != nil {
 			return nil, err
 		}
@@ -244,18 +281,30 @@ func (r *ASTNodeRepository) GetByType(ctx context.Context, fileID, nodeType stri
 	return nodes, rows.Err()
 }
 
-// Update updates an existing AST node record
+// Update updates an existing AST node record. If ParentID has changed, the
+// node's subtree is moved to the new position in ast_node_closure as part of
+// the same transaction (see moveClosureSubtreeTx).
 func (r *ASTNodeRepository) Update(ctx context.Context, node *ASTNode) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
 	query := `
-		UPDATE ast_nodes 
+		UPDATE ast_nodes
 		SET type = $3, parent_id = $4, start_line = $5, end_line = $6,
-			start_byte = $7, end_byte = $8, text = $9, attributes = $10
+			start_byte = $7, end_byte = $8, text = $9, attributes = $10, ticket = $11,
+			subtree_hash = $12
 		WHERE node_id = $1 AND file_id = $2
 	`
 
 	// Convert attributes to JSON
 	var attributesJSON []byte
-	var err error
 	if node.Attributes != nil {
 		attributesJSON, err = json.Marshal(node.Attributes)
 		if err != nil {
@@ -263,10 +312,10 @@ func (r *ASTNodeRepository) Update(ctx context.Context, node *ASTNode) error {
 		}
 	}
 
-	result, err := r.db.ExecContext(ctx, query,
+	result, err := tx.ExecContext(ctx, query,
 		node.NodeID, node.FileID, node.Type, node.ParentID,
 		node.StartLine, node.EndLine, node.StartByte, node.EndByte,
-		node.Text, attributesJSON)
+		node.Text, attributesJSON, node.Ticket, node.SubtreeHash)
 	if err != nil {
 		return err
 	}
@@ -276,12 +325,24 @@ func (r *ASTNodeRepository) Update(ctx context.Context, node *ASTNode) error {
 		return err
 	}
 	if rowsAffected == 0 {
-		return fmt.Errorf("AST node not found: %s", node.NodeID)
+		err = fmt.Errorf("AST node not found: %s", node.NodeID)
+		return err
+	}
+
+	if err = moveClosureSubtreeTx(ctx, tx, node.NodeID, node.ParentID); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
 	return nil
 }
 
-// Delete removes an AST node record
+// Delete removes an AST node record. Its ast_node_closure rows are removed
+// automatically: both ancestor_id and descendant_id reference ast_nodes(node_id)
+// ON DELETE CASCADE, so every row where the node is either ancestor or
+// descendant disappears with it.
 func (r *ASTNodeRepository) Delete(ctx context.Context, nodeID string) error {
 	query := `DELETE FROM ast_nodes WHERE node_id = $1`
 	result, err := r.db.ExecContext(ctx, query, nodeID)
@@ -299,71 +360,70 @@ func (r *ASTNodeRepository) Delete(ctx context.Context, nodeID string) error {
 	return nil
 }
 
-// BatchCreate inserts multiple AST nodes preserving parent-child relationships
+// BatchCreate inserts multiple AST nodes preserving parent-child relationships.
+// It runs in its own transaction so the nodes and their ast_node_closure rows
+// land atomically; see BatchCreateTx for the variant that joins a caller-owned
+// transaction.
 func (r *ASTNodeRepository) BatchCreate(ctx context.Context, nodes []*ASTNode) error {
 	if len(nodes) == 0 {
 		return nil
 	}
 
-	query := `
-		INSERT INTO ast_nodes (node_id, file_id, type, parent_id, start_line, end_line,
-			start_byte, end_byte, text, attributes, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
-		ON CONFLICT (node_id) 
-		DO UPDATE SET 
-			type = EXCLUDED.type,
-			parent_id = EXCLUDED.parent_id,
-			start_line = EXCLUDED.start_line,
-			end_line = EXCLUDED.end_line,
-			start_byte = EXCLUDED.start_byte,
-			end_byte = EXCLUDED.end_byte,
-			text = EXCLUDED.text,
-			attributes = EXCLUDED.attributes
-	`
-
-	stmt, err := r.db.PrepareContext(ctx, query)
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("failed to prepare batch insert statement: %w", err)
+		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
-	defer stmt.Close()
-
-	now := time.Now()
-	for _, node := range nodes {
-		node.CreatedAt = now
-
-		// Convert attributes to JSON
-		var attributesJSON []byte
-		if node.Attributes != nil {
-			attributesJSON, err = json.Marshal(node.Attributes)
-			if err != nil {
-				return fmt.Errorf("failed to marshal attributes for node %s: %w", node.NodeID, err)
-			}
-		}
-
-		_, err := stmt.ExecContext(ctx,
-			node.NodeID, node.FileID, node.Type, node.ParentID,
-			node.StartLine, node.EndLine, node.StartByte, node.EndByte,
-			node.Text, attributesJSON, node.CreatedAt)
+	defer func() {
 		if err != nil {
-			return fmt.Errorf("failed to insert AST node %s: %w", node.NodeID, err)
+			tx.Rollback()
 		}
+	}()
+
+	if err = batchCreateNodesTx(ctx, tx, nodes); err != nil {
+		return err
 	}
 
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
 	return nil
 }
 
-// BatchCreateTx inserts multiple AST nodes within a transaction
+// BatchCreateTx inserts multiple AST nodes within a caller-owned transaction,
+// maintaining ast_node_closure in the same pass: nodes are topologically
+// sorted by ParentID, then for each node (in that order) we insert its own
+// depth-0 row plus every ancestor row copied from its parent with depth+1.
+// Ancestor chains already computed earlier in this call are reused in memory;
+// a parent persisted by a previous call (e.g. an earlier batch within the same
+// streamed write) is instead looked up from ast_node_closure through tx.
 func (r *ASTNodeRepository) BatchCreateTx(ctx context.Context, tx *sql.Tx, nodes []*ASTNode) error {
+	return batchCreateNodesTx(ctx, tx, nodes)
+}
+
+func batchCreateNodesTx(ctx context.Context, tx *sql.Tx, nodes []*ASTNode) error {
 	if len(nodes) == 0 {
 		return nil
 	}
 
+	sorted := sortNodesByParent(nodes)
+
+	// BatchCreate sees the whole tree (or subtree) at once, so this is the
+	// only write path that can compute subtree_hash; Create/Update insert
+	// one node at a time and leave it nil. See Diff for how it's used.
+	hashes := computeSubtreeHashes(nodes)
+	for _, node := range sorted {
+		if h, ok := hashes[node.NodeID]; ok {
+			hashCopy := h
+			node.SubtreeHash = &hashCopy
+		}
+	}
+
 	query := `
 		INSERT INTO ast_nodes (node_id, file_id, type, parent_id, start_line, end_line,
-			start_byte, end_byte, text, attributes, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
-		ON CONFLICT (node_id) 
-		DO UPDATE SET 
+			start_byte, end_byte, text, attributes, ticket, subtree_hash, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		ON CONFLICT (node_id)
+		DO UPDATE SET
 			type = EXCLUDED.type,
 			parent_id = EXCLUDED.parent_id,
 			start_line = EXCLUDED.start_line,
@@ -371,7 +431,9 @@ func (r *ASTNodeRepository) BatchCreateTx(ctx context.Context, tx *sql.Tx, nodes
 			start_byte = EXCLUDED.start_byte,
 			end_byte = EXCLUDED.end_byte,
 			text = EXCLUDED.text,
-			attributes = EXCLUDED.attributes
+			attributes = EXCLUDED.attributes,
+			ticket = EXCLUDED.ticket,
+			subtree_hash = EXCLUDED.subtree_hash
 	`
 
 	stmt, err := tx.PrepareContext(ctx, query)
@@ -381,8 +443,10 @@ func (r *ASTNodeRepository) BatchCreateTx(ctx context.Context, tx *sql.Tx, nodes
 	defer stmt.Close()
 
 	now := time.Now()
-	for _, node := range nodes {
+	nodeIDs := make([]string, 0, len(sorted))
+	for _, node := range sorted {
 		node.CreatedAt = now
+		nodeIDs = append(nodeIDs, node.NodeID)
 
 		// Convert attributes to JSON
 		var attributesJSON []byte
@@ -393,15 +457,158 @@ func (r *ASTNodeRepository) BatchCreateTx(ctx context.Context, tx *sql.Tx, nodes
 			}
 		}
 
-		_, err := stmt.ExecContext(ctx,
+		_, err = stmt.ExecContext(ctx,
 			node.NodeID, node.FileID, node.Type, node.ParentID,
 			node.StartLine, node.EndLine, node.StartByte, node.EndByte,
-			node.Text, attributesJSON, node.CreatedAt)
+			node.Text, attributesJSON, node.Ticket, node.SubtreeHash, node.CreatedAt)
 		if err != nil {
 			return fmt.Errorf("failed to insert AST node %s: %w", node.NodeID, err)
 		}
 	}
 
+	// A re-parse may move a node under a different parent; drop its old
+	// ancestor links before the loop below recomputes them.
+	if _, err = tx.ExecContext(ctx, `
+		DELETE FROM ast_node_closure
+		WHERE descendant_id = ANY($1) AND ancestor_id != descendant_id
+	`, pq.Array(nodeIDs)); err != nil {
+		return fmt.Errorf("failed to clear stale closure rows: %w", err)
+	}
+
+	closureStmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO ast_node_closure (ancestor_id, descendant_id, depth)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (ancestor_id, descendant_id) DO UPDATE SET depth = EXCLUDED.depth
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare closure insert statement: %w", err)
+	}
+	defer closureStmt.Close()
+
+	ancestorsOf := make(map[string][]ancestorRow, len(sorted))
+	for _, node := range sorted {
+		var chain []ancestorRow
+		if node.ParentID != nil {
+			parentChain, ok := ancestorsOf[*node.ParentID]
+			if !ok {
+				parentChain, err = ancestorRowsTx(ctx, tx, *node.ParentID)
+				if err != nil {
+					return fmt.Errorf("failed to load ancestors for parent %s: %w", *node.ParentID, err)
+				}
+			}
+			for _, a := range parentChain {
+				row := ancestorRow{AncestorID: a.AncestorID, Depth: a.Depth + 1}
+				chain = append(chain, row)
+				if _, err = closureStmt.ExecContext(ctx, row.AncestorID, node.NodeID, row.Depth); err != nil {
+					return fmt.Errorf("failed to insert closure row for node %s: %w", node.NodeID, err)
+				}
+			}
+		}
+		chain = append(chain, ancestorRow{AncestorID: node.NodeID, Depth: 0})
+		if _, err = closureStmt.ExecContext(ctx, node.NodeID, node.NodeID, 0); err != nil {
+			return fmt.Errorf("failed to insert self closure row for node %s: %w", node.NodeID, err)
+		}
+		ancestorsOf[node.NodeID] = chain
+	}
+
+	return nil
+}
+
+// ancestorRow is an (ancestor_id, depth) pair read from or written to
+// ast_node_closure.
+type ancestorRow struct {
+	AncestorID string
+	Depth      int
+}
+
+// ancestorRowsTx returns the ancestor closure rows (including the self row)
+// for nodeID, read through tx so rows inserted earlier in the same
+// transaction are visible even before it commits.
+func ancestorRowsTx(ctx context.Context, tx *sql.Tx, nodeID string) ([]ancestorRow, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT ancestor_id, depth FROM ast_node_closure WHERE descendant_id = $1
+	`, nodeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []ancestorRow
+	for rows.Next() {
+		var a ancestorRow
+		if err := rows.Scan(&a.AncestorID, &a.Depth); err != nil {
+			return nil, err
+		}
+		result = append(result, a)
+	}
+	return result, rows.Err()
+}
+
+// sortNodesByParent orders nodes so that every node appears after its parent
+// (when the parent is part of the same slice), via a depth-first topological
+// sort over ParentID. Nodes whose parent is not in nodes are treated as roots
+// for ordering purposes; their real ancestors are looked up in the database.
+func sortNodesByParent(nodes []*ASTNode) []*ASTNode {
+	byID := make(map[string]*ASTNode, len(nodes))
+	for _, n := range nodes {
+		byID[n.NodeID] = n
+	}
+
+	sorted := make([]*ASTNode, 0, len(nodes))
+	visited := make(map[string]bool, len(nodes))
+
+	var visit func(n *ASTNode)
+	visit = func(n *ASTNode) {
+		if visited[n.NodeID] {
+			return
+		}
+		visited[n.NodeID] = true
+		if n.ParentID != nil {
+			if parent, ok := byID[*n.ParentID]; ok {
+				visit(parent)
+			}
+		}
+		sorted = append(sorted, n)
+	}
+	for _, n := range nodes {
+		visit(n)
+	}
+	return sorted
+}
+
+// moveClosureSubtreeTx detaches nodeID's subtree from its current ancestor
+// chain and, if newParentID is non-nil, reattaches it under the new parent.
+// This is the standard closure-table subtree-move: delete every (ancestor,
+// descendant) pair that crosses the node's old boundary, then cross-join the
+// new parent's ancestors (including itself) with the node's own descendants
+// (including itself), summing depth.
+func moveClosureSubtreeTx(ctx context.Context, tx *sql.Tx, nodeID string, newParentID *string) error {
+	if _, err := tx.ExecContext(ctx, `
+		DELETE FROM ast_node_closure
+		WHERE descendant_id IN (
+			SELECT descendant_id FROM ast_node_closure WHERE ancestor_id = $1
+		)
+		AND ancestor_id IN (
+			SELECT ancestor_id FROM ast_node_closure WHERE descendant_id = $1 AND ancestor_id != descendant_id
+		)
+	`, nodeID); err != nil {
+		return fmt.Errorf("failed to detach node from old ancestors: %w", err)
+	}
+
+	if newParentID == nil {
+		return nil
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO ast_node_closure (ancestor_id, descendant_id, depth)
+		SELECT p.ancestor_id, c.descendant_id, p.depth + c.depth + 1
+		FROM ast_node_closure p
+		JOIN ast_node_closure c ON c.ancestor_id = $1
+		WHERE p.descendant_id = $2
+		ON CONFLICT (ancestor_id, descendant_id) DO UPDATE SET depth = EXCLUDED.depth
+	`, nodeID, *newParentID); err != nil {
+		return fmt.Errorf("failed to reattach node under new parent: %w", err)
+	}
 	return nil
 }
 
@@ -447,28 +654,16 @@ func (r *ASTNodeRepository) CountByType(ctx context.Context, fileID string) (map
 	return counts, rows.Err()
 }
 
-// GetNodeHierarchy retrieves a node and all its descendants
+// GetNodeHierarchy retrieves a node and all its descendants, read from the
+// materialized ast_node_closure table instead of a recursive CTE.
 func (r *ASTNodeRepository) GetNodeHierarchy(ctx context.Context, nodeID string) ([]*ASTNode, error) {
 	query := `
-		WITH RECURSIVE node_hierarchy AS (
-			-- Base case: start with the specified node
-			SELECT node_id, file_id, type, parent_id, start_line, end_line,
-				start_byte, end_byte, text, attributes, created_at, 0 as level
-			FROM ast_nodes 
-			WHERE node_id = $1
-			
-			UNION ALL
-			
-			-- Recursive case: find children
-			SELECT n.node_id, n.file_id, n.type, n.parent_id, n.start_line, n.end_line,
-				n.start_byte, n.end_byte, n.text, n.attributes, n.created_at, h.level + 1
-			FROM ast_nodes n
-			INNER JOIN node_hierarchy h ON n.parent_id = h.node_id
-		)
-		SELECT node_id, file_id, type, parent_id, start_line, end_line,
-			start_byte, end_byte, text, attributes, created_at
-		FROM node_hierarchy
-		ORDER BY level, start_line, start_byte
+		SELECT n.node_id, n.file_id, n.type, n.parent_id, n.start_line, n.end_line,
+			n.start_byte, n.end_byte, n.text, n.attributes, n.ticket, n.subtree_hash, n.created_at
+		FROM ast_nodes n
+		JOIN ast_node_closure c ON c.descendant_id = n.node_id
+		WHERE c.ancestor_id = $1
+		ORDER BY c.depth, n.start_line, n.start_byte
 	`
 	rows, err := r.db.QueryContext(ctx, query, nodeID)
 	if err != nil {
@@ -483,7 +678,7 @@ func (r *ASTNodeRepository) GetNodeHierarchy(ctx context.Context, nodeID string)
 		err := rows.Scan(
 			&node.NodeID, &node.FileID, &node.Type, &node.ParentID,
 			&node.StartLine, &node.EndLine, &node.StartByte, &node.EndByte,
-			&node.Text, &attributesJSON, &node.CreatedAt)
+			&node.Text, &attributesJSON, &node.Ticket, &node.SubtreeHash, &node.CreatedAt)
 		if err != nil {
 			return nil, err
 		}
@@ -500,3 +695,126 @@ func (r *ASTNodeRepository) GetNodeHierarchy(ctx context.Context, nodeID string)
 	}
 	return nodes, rows.Err()
 }
+
+// GetAncestors retrieves every ancestor of a node (its parent chain up to the
+// file root), ordered from nearest to furthest, using ast_node_closure.
+func (r *ASTNodeRepository) GetAncestors(ctx context.Context, nodeID string) ([]*ASTNode, error) {
+	query := `
+		SELECT n.node_id, n.file_id, n.type, n.parent_id, n.start_line, n.end_line,
+			n.start_byte, n.end_byte, n.text, n.attributes, n.ticket, n.subtree_hash, n.created_at
+		FROM ast_nodes n
+		JOIN ast_node_closure c ON c.ancestor_id = n.node_id
+		WHERE c.descendant_id = $1 AND c.ancestor_id != c.descendant_id
+		ORDER BY c.depth
+	`
+	rows, err := r.db.QueryContext(ctx, query, nodeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var nodes []*ASTNode
+	for rows.Next() {
+		var node ASTNode
+		var attributesJSON []byte
+		err := rows.Scan(
+			&node.NodeID, &node.FileID, &node.Type, &node.ParentID,
+			&node.StartLine, &node.EndLine, &node.StartByte, &node.EndByte,
+			&node.Text, &attributesJSON, &node.Ticket, &node.SubtreeHash, &node.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		// Unmarshal attributes
+		if attributesJSON != nil {
+			err = json.Unmarshal(attributesJSON, &node.Attributes)
+			if err != nil {
+				return nil, fmt.Errorf("failed to unmarshal attributes: %w", err)
+			}
+		}
+
+		nodes = append(nodes, &node)
+	}
+	return nodes, rows.Err()
+}
+
+// GetDescendantsByType retrieves descendants of a node filtered by type,
+// using ast_node_closure. maxDepth bounds how many levels below the node to
+// consider; maxDepth <= 0 means unbounded.
+func (r *ASTNodeRepository) GetDescendantsByType(ctx context.Context, nodeID, nodeType string, maxDepth int) ([]*ASTNode, error) {
+	query := `
+		SELECT n.node_id, n.file_id, n.type, n.parent_id, n.start_line, n.end_line,
+			n.start_byte, n.end_byte, n.text, n.attributes, n.ticket, n.subtree_hash, n.created_at
+		FROM ast_nodes n
+		JOIN ast_node_closure c ON c.descendant_id = n.node_id
+		WHERE c.ancestor_id = $1 AND c.ancestor_id != c.descendant_id AND n.type = $2
+	`
+	args := []interface{}{nodeID, nodeType}
+	if maxDepth > 0 {
+		query += " AND c.depth <= $3"
+		args = append(args, maxDepth)
+	}
+	query += " ORDER BY c.depth, n.start_line, n.start_byte"
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var nodes []*ASTNode
+	for rows.Next() {
+		var node ASTNode
+		var attributesJSON []byte
+		err := rows.Scan(
+			&node.NodeID, &node.FileID, &node.Type, &node.ParentID,
+			&node.StartLine, &node.EndLine, &node.StartByte, &node.EndByte,
+			&node.Text, &attributesJSON, &node.Ticket, &node.SubtreeHash, &node.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		// Unmarshal attributes
+		if attributesJSON != nil {
+			err = json.Unmarshal(attributesJSON, &node.Attributes)
+			if err != nil {
+				return nil, fmt.Errorf("failed to unmarshal attributes: %w", err)
+			}
+		}
+
+		nodes = append(nodes, &node)
+	}
+	return nodes, rows.Err()
+}
+
+// GetByTicket retrieves an AST node by its canonical ticket (see FormatTicket).
+// It returns (nil, nil) if no node has that ticket.
+func (r *ASTNodeRepository) GetByTicket(ctx context.Context, ticket string) (*ASTNode, error) {
+	query := `
+		SELECT node_id, file_id, type, parent_id, start_line, end_line,
+			start_byte, end_byte, text, attributes, ticket, subtree_hash, created_at
+		FROM ast_nodes WHERE ticket = $1
+	`
+	var node ASTNode
+	var attributesJSON []byte
+	err := r.db.QueryRowContext(ctx, query, ticket).Scan(
+		&node.NodeID, &node.FileID, &node.Type, &node.ParentID,
+		&node.StartLine, &node.EndLine, &node.StartByte, &node.EndByte,
+		&node.Text, &attributesJSON, &node.Ticket, &node.SubtreeHash, &node.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	// Unmarshal attributes
+	if attributesJSON != nil {
+		err = json.Unmarshal(attributesJSON, &node.Attributes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal attributes: %w", err)
+		}
+	}
+
+	return &node, nil
+}
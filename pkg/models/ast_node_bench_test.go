@@ -0,0 +1,167 @@
+package models
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// seedHierarchyFixture creates a repository, file, and a balanced AST tree of
+// roughly nodeCount nodes (branching factor 10) so hierarchy lookups on the
+// root node have to walk a large subtree. It returns the file ID and the root
+// node ID.
+func seedHierarchyFixture(b *testing.B, testDB *TestDB, nodeCount int) (string, string) {
+	b.Helper()
+
+	ctx := context.Background()
+	repoRepo := NewRepositoryRepository(testDB.DB)
+	fileRepo := NewFileRepository(testDB.DB)
+	nodeRepo := NewASTNodeRepository(testDB.DB)
+
+	repo := &Repository{
+		RepoID: uuid.New().String(),
+		Name:   "bench-repo-hierarchy",
+		URL:    "https://github.com/test/repo",
+		Branch: "main",
+	}
+	if err := repoRepo.Create(ctx, repo); err != nil {
+		b.Fatalf("failed to create repository: %v", err)
+	}
+
+	file := &File{
+		FileID:   uuid.New().String(),
+		RepoID:   repo.RepoID,
+		Path:     "src/huge.go",
+		Language: "go",
+		Size:     int64(nodeCount) * 40,
+		Checksum: "bench",
+	}
+	if err := fileRepo.Create(ctx, file); err != nil {
+		b.Fatalf("failed to create file: %v", err)
+	}
+
+	const branchingFactor = 10
+
+	root := &ASTNode{
+		NodeID:    uuid.New().String(),
+		FileID:    file.FileID,
+		Type:      "source_file",
+		StartLine: 1,
+		EndLine:   nodeCount,
+		StartByte: 0,
+		EndByte:   nodeCount * 40,
+		Text:      "root",
+	}
+
+	nodes := []*ASTNode{root}
+	frontier := []*ASTNode{root}
+	line := 2
+
+	for len(nodes) < nodeCount {
+		var next []*ASTNode
+		for _, parent := range frontier {
+			for i := 0; i < branchingFactor && len(nodes) < nodeCount; i++ {
+				parentID := parent.NodeID
+				child := &ASTNode{
+					NodeID:    uuid.New().String(),
+					FileID:    file.FileID,
+					Type:      "expression_statement",
+					ParentID:  &parentID,
+					StartLine: line,
+					EndLine:   line,
+					StartByte: line * 40,
+					EndByte:   line*40 + 40,
+					Text:      "node",
+				}
+				line++
+				nodes = append(nodes, child)
+				next = append(next, child)
+			}
+		}
+		frontier = next
+	}
+
+	const writeBatchSize = 1000
+	for i := 0; i < len(nodes); i += writeBatchSize {
+		end := i + writeBatchSize
+		if end > len(nodes) {
+			end = len(nodes)
+		}
+		if err := nodeRepo.BatchCreate(ctx, nodes[i:end]); err != nil {
+			b.Fatalf("failed to seed AST nodes: %v", err)
+		}
+	}
+
+	return file.FileID, root.NodeID
+}
+
+// BenchmarkGetNodeHierarchy_Closure measures GetNodeHierarchy reading the
+// materialized ast_node_closure table on a ~100k-node tree.
+func BenchmarkGetNodeHierarchy_Closure(b *testing.B) {
+	if testing.Short() {
+		b.Skip("Skipping integration benchmark in short mode")
+	}
+
+	testDB := SetupTestDB(b)
+	defer testDB.TeardownTestDB(b)
+
+	nodeRepo := NewASTNodeRepository(testDB.DB)
+	_, rootID := seedHierarchyFixture(b, testDB, 100000)
+
+	ctx := context.Background()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := nodeRepo.GetNodeHierarchy(ctx, rootID); err != nil {
+			b.Fatalf("GetNodeHierarchy failed: %v", err)
+		}
+	}
+}
+
+// BenchmarkGetNodeHierarchy_RecursiveCTE measures the same traversal using the
+// recursive CTE GetNodeHierarchy was rewritten from, for comparison against
+// BenchmarkGetNodeHierarchy_Closure.
+func BenchmarkGetNodeHierarchy_RecursiveCTE(b *testing.B) {
+	if testing.Short() {
+		b.Skip("Skipping integration benchmark in short mode")
+	}
+
+	testDB := SetupTestDB(b)
+	defer testDB.TeardownTestDB(b)
+
+	_, rootID := seedHierarchyFixture(b, testDB, 100000)
+
+	const query = `
+		WITH RECURSIVE node_hierarchy AS (
+			SELECT node_id, file_id, type, parent_id, start_line, end_line,
+				start_byte, end_byte, text, attributes, ticket, created_at, 0 as level
+			FROM ast_nodes
+			WHERE node_id = $1
+
+			UNION ALL
+
+			SELECT n.node_id, n.file_id, n.type, n.parent_id, n.start_line, n.end_line,
+				n.start_byte, n.end_byte, n.text, n.attributes, n.ticket, n.created_at, h.level + 1
+			FROM ast_nodes n
+			INNER JOIN node_hierarchy h ON n.parent_id = h.node_id
+		)
+		SELECT node_id FROM node_hierarchy ORDER BY level, start_line, start_byte
+	`
+
+	ctx := context.Background()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		rows, err := testDB.QueryContext(ctx, query, rootID)
+		if err != nil {
+			b.Fatalf("recursive CTE query failed: %v", err)
+		}
+		count := 0
+		for rows.Next() {
+			count++
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			b.Fatalf("recursive CTE row iteration failed: %v", err)
+		}
+	}
+}
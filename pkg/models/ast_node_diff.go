@@ -0,0 +1,312 @@
+package models
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// topDownSimilarityThreshold is the minimum dice coefficient of child-hash
+// multisets required for Diff's top-down pass to match two unmatched nodes
+// of the same type.
+const topDownSimilarityThreshold = 0.5
+
+// ASTNodeChangeType classifies how a node changed between two parses of the
+// same file, as computed by ASTNodeRepository.Diff.
+type ASTNodeChangeType string
+
+const (
+	ASTNodeChangeInserted ASTNodeChangeType = "inserted"
+	ASTNodeChangeDeleted  ASTNodeChangeType = "deleted"
+	ASTNodeChangeUpdated  ASTNodeChangeType = "updated"
+	ASTNodeChangeMoved    ASTNodeChangeType = "moved"
+)
+
+// ASTNodeChange describes one node-level difference between an old and new
+// parse of the same file, as returned by ASTNodeRepository.Diff. OldNode is
+// nil for an insert; NewNode is nil for a delete.
+type ASTNodeChange struct {
+	Type    ASTNodeChangeType `json:"type"`
+	OldNode *ASTNode          `json:"old_node,omitempty"`
+	NewNode *ASTNode          `json:"new_node,omitempty"`
+}
+
+// Diff computes the AST-level changes between two parses of the same path
+// (e.g. oldFileID and newFileID are the file rows for the same path before
+// and after a re-index). It matches unchanged subtrees bottom-up by their
+// subtree_hash in O(n), then matches whatever's left top-down by
+// (type, parent match, child-hash similarity); anything still unmatched is
+// an insert or delete. The result is meant to drive DependencyEdge
+// invalidation: a caller only needs to recompute edges touching nodes that
+// appear in the returned changes, not the whole file.
+func (r *ASTNodeRepository) Diff(ctx context.Context, oldFileID, newFileID string) ([]ASTNodeChange, error) {
+	oldNodes, err := r.GetByFileID(ctx, oldFileID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load nodes for old file %s: %w", oldFileID, err)
+	}
+	newNodes, err := r.GetByFileID(ctx, newFileID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load nodes for new file %s: %w", newFileID, err)
+	}
+	return diffASTNodes(oldNodes, newNodes), nil
+}
+
+// astNodeTreeIndex bundles the lookups diffASTNodes and its helpers need
+// over one side (old or new) of a Diff call.
+type astNodeTreeIndex struct {
+	byID       map[string]*ASTNode
+	childrenOf map[string][]*ASTNode // ordered by (start_line, start_byte)
+	hashes     map[string]string
+}
+
+func buildASTNodeTreeIndex(nodes []*ASTNode) *astNodeTreeIndex {
+	idx := &astNodeTreeIndex{
+		byID:       make(map[string]*ASTNode, len(nodes)),
+		childrenOf: make(map[string][]*ASTNode),
+		hashes:     computeSubtreeHashes(nodes),
+	}
+	for _, n := range nodes {
+		idx.byID[n.NodeID] = n
+		if n.ParentID != nil {
+			idx.childrenOf[*n.ParentID] = append(idx.childrenOf[*n.ParentID], n)
+		}
+	}
+	for _, kids := range idx.childrenOf {
+		sortByPosition(kids)
+	}
+	return idx
+}
+
+func sortByPosition(nodes []*ASTNode) {
+	sort.Slice(nodes, func(i, j int) bool {
+		if nodes[i].StartLine != nodes[j].StartLine {
+			return nodes[i].StartLine < nodes[j].StartLine
+		}
+		return nodes[i].StartByte < nodes[j].StartByte
+	})
+}
+
+// computeSubtreeHashes returns, for every node in nodes, a hash of its
+// subtree: the node's type, its children's hashes (sorted, so the result is
+// insensitive to how the DB happened to order same-depth siblings), and its
+// normalized text. Identical subtrees - including leaves - get identical
+// hashes, which is what lets Diff match them in a single map lookup instead
+// of a pairwise comparison.
+func computeSubtreeHashes(nodes []*ASTNode) map[string]string {
+	childrenOf := make(map[string][]*ASTNode, len(nodes))
+	for _, n := range nodes {
+		if n.ParentID != nil {
+			childrenOf[*n.ParentID] = append(childrenOf[*n.ParentID], n)
+		}
+	}
+	for _, kids := range childrenOf {
+		sortByPosition(kids)
+	}
+
+	hashes := make(map[string]string, len(nodes))
+	var visit func(n *ASTNode) string
+	visit = func(n *ASTNode) string {
+		if h, ok := hashes[n.NodeID]; ok {
+			return h
+		}
+		childHashes := make([]string, 0, len(childrenOf[n.NodeID]))
+		for _, child := range childrenOf[n.NodeID] {
+			childHashes = append(childHashes, visit(child))
+		}
+		sort.Strings(childHashes)
+
+		h := sha256.New()
+		h.Write([]byte(n.Type))
+		h.Write([]byte{0})
+		for _, ch := range childHashes {
+			h.Write([]byte(ch))
+		}
+		h.Write([]byte{0})
+		h.Write([]byte(normalizeNodeText(n.Text)))
+
+		sum := hex.EncodeToString(h.Sum(nil))
+		hashes[n.NodeID] = sum
+		return sum
+	}
+
+	for _, n := range nodes {
+		visit(n)
+	}
+	return hashes
+}
+
+// normalizeNodeText collapses runs of whitespace so two subtrees that only
+// differ in formatting (reindentation, trailing newline) still hash equal.
+func normalizeNodeText(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// diffASTNodes is the pure, DB-free core of Diff: given the two node sets,
+// it returns the list of changes between them. Kept separate from Diff so
+// the matching logic can be unit tested without a database.
+func diffASTNodes(oldNodes, newNodes []*ASTNode) []ASTNodeChange {
+	oldIdx := buildASTNodeTreeIndex(oldNodes)
+	newIdx := buildASTNodeTreeIndex(newNodes)
+
+	oldToNew := make(map[string]string)
+	newToOld := make(map[string]string)
+
+	matchIdenticalSubtrees(oldNodes, newNodes, oldIdx.hashes, newIdx.hashes, oldToNew, newToOld)
+	matchByTopDownSimilarity(oldNodes, newNodes, oldIdx, newIdx, oldToNew, newToOld)
+
+	var changes []ASTNodeChange
+	for _, old := range oldNodes {
+		newID, ok := oldToNew[old.NodeID]
+		if !ok {
+			changes = append(changes, ASTNodeChange{Type: ASTNodeChangeDeleted, OldNode: old})
+			continue
+		}
+
+		newNode := newIdx.byID[newID]
+		switch {
+		case astNodeParentChanged(old, newNode, oldToNew):
+			changes = append(changes, ASTNodeChange{Type: ASTNodeChangeMoved, OldNode: old, NewNode: newNode})
+		case old.Type != newNode.Type || old.Text != newNode.Text:
+			changes = append(changes, ASTNodeChange{Type: ASTNodeChangeUpdated, OldNode: old, NewNode: newNode})
+		}
+		// Matched, same parent, same type and text: unchanged, no entry.
+	}
+
+	for _, n := range newNodes {
+		if _, ok := newToOld[n.NodeID]; !ok {
+			changes = append(changes, ASTNodeChange{Type: ASTNodeChangeInserted, NewNode: n})
+		}
+	}
+
+	return changes
+}
+
+// matchIdenticalSubtrees pairs up old and new nodes that share a
+// subtree_hash, i.e. their whole subtree is byte-for-byte identical. Nodes
+// are grouped by hash (an O(n) map build) and paired off in tree-position
+// order within each group, so this never degrades to the pairwise
+// comparison a naive diff would need.
+func matchIdenticalSubtrees(oldNodes, newNodes []*ASTNode, oldHashes, newHashes map[string]string, oldToNew, newToOld map[string]string) {
+	oldByHash := make(map[string][]*ASTNode)
+	for _, n := range oldNodes {
+		h := oldHashes[n.NodeID]
+		oldByHash[h] = append(oldByHash[h], n)
+	}
+	newByHash := make(map[string][]*ASTNode)
+	for _, n := range newNodes {
+		h := newHashes[n.NodeID]
+		newByHash[h] = append(newByHash[h], n)
+	}
+
+	for h, oldGroup := range oldByHash {
+		newGroup, ok := newByHash[h]
+		if !ok {
+			continue
+		}
+		sortByPosition(oldGroup)
+		sortByPosition(newGroup)
+		for i := 0; i < len(oldGroup) && i < len(newGroup); i++ {
+			oldToNew[oldGroup[i].NodeID] = newGroup[i].NodeID
+			newToOld[newGroup[i].NodeID] = oldGroup[i].NodeID
+		}
+	}
+}
+
+// matchByTopDownSimilarity matches whatever matchIdenticalSubtrees left
+// unmatched: for each remaining old node, it picks the unmatched new node
+// of the same type whose immediate children have the most similar
+// subtree_hash multiset (dice coefficient), as long as that similarity
+// clears topDownSimilarityThreshold.
+func matchByTopDownSimilarity(oldNodes, newNodes []*ASTNode, oldIdx, newIdx *astNodeTreeIndex, oldToNew, newToOld map[string]string) {
+	var unmatchedNew []*ASTNode
+	for _, n := range newNodes {
+		if _, ok := newToOld[n.NodeID]; !ok {
+			unmatchedNew = append(unmatchedNew, n)
+		}
+	}
+	if len(unmatchedNew) == 0 {
+		return
+	}
+	sortByPosition(unmatchedNew)
+
+	var unmatchedOld []*ASTNode
+	for _, n := range oldNodes {
+		if _, ok := oldToNew[n.NodeID]; !ok {
+			unmatchedOld = append(unmatchedOld, n)
+		}
+	}
+	sortByPosition(unmatchedOld)
+
+	for _, old := range unmatchedOld {
+		var best *ASTNode
+		bestScore := 0.0
+		for _, candidate := range unmatchedNew {
+			if _, taken := newToOld[candidate.NodeID]; taken {
+				continue
+			}
+			if candidate.Type != old.Type {
+				continue
+			}
+			score := childHashSimilarity(old, candidate, oldIdx, newIdx)
+			if score > bestScore {
+				bestScore = score
+				best = candidate
+			}
+		}
+		if best != nil && bestScore >= topDownSimilarityThreshold {
+			oldToNew[old.NodeID] = best.NodeID
+			newToOld[best.NodeID] = old.NodeID
+		}
+	}
+}
+
+// childHashSimilarity is the dice coefficient of a and b's immediate
+// children, compared by subtree_hash as a multiset: 2*|intersection| /
+// (|children(a)| + |children(b)|). Childless nodes of the same type (e.g.
+// two identifiers with different names) are treated as trivially similar so
+// leaves can still be matched top-down.
+func childHashSimilarity(a, b *ASTNode, oldIdx, newIdx *astNodeTreeIndex) float64 {
+	aChildren := oldIdx.childrenOf[a.NodeID]
+	bChildren := newIdx.childrenOf[b.NodeID]
+	if len(aChildren) == 0 && len(bChildren) == 0 {
+		return 1.0
+	}
+
+	remaining := make(map[string]int, len(aChildren))
+	for _, c := range aChildren {
+		remaining[oldIdx.hashes[c.NodeID]]++
+	}
+
+	intersection := 0
+	for _, c := range bChildren {
+		h := newIdx.hashes[c.NodeID]
+		if remaining[h] > 0 {
+			remaining[h]--
+			intersection++
+		}
+	}
+
+	return 2 * float64(intersection) / float64(len(aChildren)+len(bChildren))
+}
+
+// astNodeParentChanged reports whether newNode's parent differs from where
+// old's matched parent ended up, i.e. the node moved to a different place
+// in the tree rather than just changing its own text.
+func astNodeParentChanged(old, newNode *ASTNode, oldToNew map[string]string) bool {
+	if old.ParentID == nil && newNode.ParentID == nil {
+		return false
+	}
+	if old.ParentID == nil || newNode.ParentID == nil {
+		return true
+	}
+	expectedNewParent, ok := oldToNew[*old.ParentID]
+	if !ok {
+		// The old parent itself wasn't matched (e.g. it was replaced
+		// wholesale); treat that as a move rather than guessing.
+		return true
+	}
+	return expectedNewParent != *newNode.ParentID
+}
@@ -0,0 +1,146 @@
+package models
+
+import (
+	"testing"
+)
+
+func strPtr(s string) *string { return &s }
+
+func TestDiffASTNodesUnchanged(t *testing.T) {
+	old := []*ASTNode{
+		{NodeID: "root", Type: "module", Text: "a+b"},
+		{NodeID: "a", Type: "identifier", Text: "a", ParentID: strPtr("root")},
+		{NodeID: "b", Type: "identifier", Text: "b", ParentID: strPtr("root")},
+	}
+	newNodes := []*ASTNode{
+		{NodeID: "root2", Type: "module", Text: "a+b"},
+		{NodeID: "a2", Type: "identifier", Text: "a", ParentID: strPtr("root2")},
+		{NodeID: "b2", Type: "identifier", Text: "b", ParentID: strPtr("root2")},
+	}
+
+	changes := diffASTNodes(old, newNodes)
+	if len(changes) != 0 {
+		t.Fatalf("expected no changes for identical subtrees, got %d: %+v", len(changes), changes)
+	}
+}
+
+func TestDiffASTNodesInsert(t *testing.T) {
+	old := []*ASTNode{
+		{NodeID: "root", Type: "module", Text: "a"},
+		{NodeID: "a", Type: "identifier", Text: "a", ParentID: strPtr("root")},
+	}
+	newNodes := []*ASTNode{
+		{NodeID: "root2", Type: "module", Text: "a b"},
+		{NodeID: "a2", Type: "identifier", Text: "a", ParentID: strPtr("root2")},
+		{NodeID: "b2", Type: "identifier", Text: "b", ParentID: strPtr("root2")},
+	}
+
+	changes := diffASTNodes(old, newNodes)
+	var inserted []ASTNodeChange
+	for _, c := range changes {
+		if c.Type == ASTNodeChangeInserted {
+			inserted = append(inserted, c)
+		}
+	}
+	if len(inserted) != 1 || inserted[0].NewNode.NodeID != "b2" {
+		t.Fatalf("expected a single insert of b2, got %+v", changes)
+	}
+}
+
+func TestDiffASTNodesDelete(t *testing.T) {
+	old := []*ASTNode{
+		{NodeID: "root", Type: "module", Text: "a b"},
+		{NodeID: "a", Type: "identifier", Text: "a", ParentID: strPtr("root")},
+		{NodeID: "b", Type: "identifier", Text: "b", ParentID: strPtr("root")},
+	}
+	newNodes := []*ASTNode{
+		{NodeID: "root2", Type: "module", Text: "a"},
+		{NodeID: "a2", Type: "identifier", Text: "a", ParentID: strPtr("root2")},
+	}
+
+	changes := diffASTNodes(old, newNodes)
+	var deleted []ASTNodeChange
+	for _, c := range changes {
+		if c.Type == ASTNodeChangeDeleted {
+			deleted = append(deleted, c)
+		}
+	}
+	if len(deleted) != 1 || deleted[0].OldNode.NodeID != "b" {
+		t.Fatalf("expected a single delete of b, got %+v", changes)
+	}
+}
+
+func TestDiffASTNodesUpdate(t *testing.T) {
+	old := []*ASTNode{
+		{NodeID: "root", Type: "module", Text: "x=1"},
+		{NodeID: "lit", Type: "number_literal", Text: "1", ParentID: strPtr("root")},
+	}
+	newNodes := []*ASTNode{
+		{NodeID: "root2", Type: "module", Text: "x=2"},
+		{NodeID: "lit2", Type: "number_literal", Text: "2", ParentID: strPtr("root2")},
+	}
+
+	changes := diffASTNodes(old, newNodes)
+	var updated []ASTNodeChange
+	for _, c := range changes {
+		if c.Type == ASTNodeChangeUpdated {
+			updated = append(updated, c)
+		}
+	}
+	if len(updated) != 2 {
+		t.Fatalf("expected root and literal both updated, got %+v", changes)
+	}
+}
+
+func TestDiffASTNodesMove(t *testing.T) {
+	old := []*ASTNode{
+		{NodeID: "root", Type: "module", Text: "if c { x } y"},
+		{NodeID: "ifstmt", Type: "if_statement", Text: "if c { x }", ParentID: strPtr("root")},
+		{NodeID: "x", Type: "identifier", Text: "x", ParentID: strPtr("ifstmt")},
+		{NodeID: "y", Type: "identifier", Text: "y", ParentID: strPtr("root")},
+	}
+	// x moved out of the if_statement to be a direct child of root.
+	newNodes := []*ASTNode{
+		{NodeID: "root2", Type: "module", Text: "if c {  } y x"},
+		{NodeID: "ifstmt2", Type: "if_statement", Text: "if c {  }", ParentID: strPtr("root2")},
+		{NodeID: "y2", Type: "identifier", Text: "y", ParentID: strPtr("root2")},
+		{NodeID: "x2", Type: "identifier", Text: "x", ParentID: strPtr("root2")},
+	}
+
+	changes := diffASTNodes(old, newNodes)
+	found := false
+	for _, c := range changes {
+		if c.Type == ASTNodeChangeMoved && c.OldNode.NodeID == "x" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("expected x to be reported as moved, got %+v", changes)
+	}
+}
+
+func TestComputeSubtreeHashesMatchesIdenticalSubtrees(t *testing.T) {
+	a := []*ASTNode{
+		{NodeID: "1", Type: "binary_expr", Text: "a+b"},
+		{NodeID: "2", Type: "identifier", Text: "a", ParentID: strPtr("1")},
+		{NodeID: "3", Type: "identifier", Text: "b", ParentID: strPtr("1")},
+	}
+	b := []*ASTNode{
+		{NodeID: "10", Type: "binary_expr", Text: "a+b"},
+		{NodeID: "11", Type: "identifier", Text: "a", ParentID: strPtr("10")},
+		{NodeID: "12", Type: "identifier", Text: "b", ParentID: strPtr("10")},
+	}
+
+	hashesA := computeSubtreeHashes(a)
+	hashesB := computeSubtreeHashes(b)
+
+	if hashesA["1"] != hashesB["10"] {
+		t.Fatalf("expected identical subtrees to hash equal: %s != %s", hashesA["1"], hashesB["10"])
+	}
+	if hashesA["2"] != hashesB["11"] {
+		t.Fatalf("expected identical leaves to hash equal: %s != %s", hashesA["2"], hashesB["11"])
+	}
+	if hashesA["2"] == hashesA["3"] {
+		t.Fatalf("expected distinct leaves to hash differently")
+	}
+}
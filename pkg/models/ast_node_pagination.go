@@ -0,0 +1,194 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+)
+
+// astNodeCursor is the decoded form of an opaque ListPage cursor: the
+// (start_line, start_byte, node_id) position of the last row returned by the
+// previous page, used as the lower bound for keyset pagination.
+type astNodeCursor struct {
+	StartLine int    `json:"l"`
+	StartByte int    `json:"b"`
+	NodeID    string `json:"n"`
+}
+
+// EncodeASTNodeCursor renders a cursor position as the opaque base64 string
+// returned as ListPage's nextCursor.
+func EncodeASTNodeCursor(startLine, startByte int, nodeID string) string {
+	data, _ := json.Marshal(astNodeCursor{StartLine: startLine, StartByte: startByte, NodeID: nodeID})
+	return base64.URLEncoding.EncodeToString(data)
+}
+
+// DecodeASTNodeCursor parses a cursor string produced by EncodeASTNodeCursor.
+func DecodeASTNodeCursor(cursor string) (startLine, startByte int, nodeID string, err error) {
+	data, err := base64.URLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, 0, "", fmt.Errorf("invalid cursor %q: %w", cursor, err)
+	}
+	var c astNodeCursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return 0, 0, "", fmt.Errorf("invalid cursor %q: %w", cursor, err)
+	}
+	return c.StartLine, c.StartByte, c.NodeID, nil
+}
+
+// ASTNodeIterator streams ast_nodes rows one at a time from a Stream* query
+// instead of buffering the whole result set into a []*ASTNode, so callers
+// like the JSON emission path can process files with millions of nodes
+// without holding them all in memory at once. Callers must always call
+// Close, even if Next returns an error partway through.
+type ASTNodeIterator struct {
+	rows *sql.Rows
+}
+
+// Next advances the iterator and returns the next node, or (nil, nil) once
+// the iterator is exhausted.
+func (it *ASTNodeIterator) Next(ctx context.Context) (*ASTNode, error) {
+	if !it.rows.Next() {
+		return nil, it.rows.Err()
+	}
+
+	var node ASTNode
+	var attributesJSON []byte
+	if err := it.rows.Scan(
+		&node.NodeID, &node.FileID, &node.Type, &node.ParentID,
+		&node.StartLine, &node.EndLine, &node.StartByte, &node.EndByte,
+		&node.Text, &attributesJSON, &node.Ticket, &node.SubtreeHash, &node.CreatedAt); err != nil {
+		return nil, err
+	}
+
+	if attributesJSON != nil {
+		if err := json.Unmarshal(attributesJSON, &node.Attributes); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal attributes: %w", err)
+		}
+	}
+
+	return &node, nil
+}
+
+// Close releases the underlying rows. Safe to call more than once.
+func (it *ASTNodeIterator) Close() error {
+	return it.rows.Close()
+}
+
+// StreamByFileID is the streaming counterpart to GetByFileID: it returns an
+// iterator over a file's AST nodes instead of a fully buffered slice.
+func (r *ASTNodeRepository) StreamByFileID(ctx context.Context, fileID string) (*ASTNodeIterator, error) {
+	query := `
+		SELECT node_id, file_id, type, parent_id, start_line, end_line,
+			start_byte, end_byte, text, attributes, ticket, subtree_hash, created_at
+		FROM ast_nodes WHERE file_id = $1 ORDER BY start_line, start_byte, node_id
+	`
+	rows, err := r.db.QueryContext(ctx, query, fileID)
+	if err != nil {
+		return nil, err
+	}
+	return &ASTNodeIterator{rows: rows}, nil
+}
+
+// StreamByType is the streaming counterpart to GetByType.
+func (r *ASTNodeRepository) StreamByType(ctx context.Context, fileID, nodeType string) (*ASTNodeIterator, error) {
+	query := `
+		SELECT node_id, file_id, type, parent_id, start_line, end_line,
+			start_byte, end_byte, text, attributes, ticket, subtree_hash, created_at
+		FROM ast_nodes WHERE file_id = $1 AND type = $2 ORDER BY start_line, start_byte, node_id
+	`
+	rows, err := r.db.QueryContext(ctx, query, fileID, nodeType)
+	if err != nil {
+		return nil, err
+	}
+	return &ASTNodeIterator{rows: rows}, nil
+}
+
+// StreamByParentID is the streaming counterpart to GetByParentID.
+func (r *ASTNodeRepository) StreamByParentID(ctx context.Context, parentID string) (*ASTNodeIterator, error) {
+	query := `
+		SELECT node_id, file_id, type, parent_id, start_line, end_line,
+			start_byte, end_byte, text, attributes, ticket, subtree_hash, created_at
+		FROM ast_nodes WHERE parent_id = $1 ORDER BY start_line, start_byte, node_id
+	`
+	rows, err := r.db.QueryContext(ctx, query, parentID)
+	if err != nil {
+		return nil, err
+	}
+	return &ASTNodeIterator{rows: rows}, nil
+}
+
+// defaultListPageLimit is used when ListPage is called with a non-positive
+// limit.
+const defaultListPageLimit = 100
+
+// ListPage returns one page of a file's AST nodes ordered by
+// (start_line, start_byte, node_id), using keyset pagination (a WHERE ...
+// > (...) predicate on the composite index) rather than OFFSET, so page N+1
+// costs the same as page 1 regardless of how deep N is. cursor is the
+// opaque string returned as nextCursor by a previous call; pass "" to fetch
+// the first page. nextCursor is "" once there are no more rows.
+func (r *ASTNodeRepository) ListPage(ctx context.Context, fileID string, cursor string, limit int) ([]*ASTNode, string, error) {
+	if limit <= 0 {
+		limit = defaultListPageLimit
+	}
+
+	query := `
+		SELECT node_id, file_id, type, parent_id, start_line, end_line,
+			start_byte, end_byte, text, attributes, ticket, subtree_hash, created_at
+		FROM ast_nodes
+		WHERE file_id = $1
+	`
+	args := []interface{}{fileID}
+
+	if cursor != "" {
+		startLine, startByte, nodeID, err := DecodeASTNodeCursor(cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		query += " AND (start_line, start_byte, node_id) > ($2, $3, $4)"
+		args = append(args, startLine, startByte, nodeID)
+	}
+
+	// Fetch one extra row so we can tell whether another page follows
+	// without a separate COUNT query.
+	query += fmt.Sprintf(" ORDER BY start_line, start_byte, node_id LIMIT $%d", len(args)+1)
+	args = append(args, limit+1)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+
+	var nodes []*ASTNode
+	for rows.Next() {
+		var node ASTNode
+		var attributesJSON []byte
+		if err := rows.Scan(
+			&node.NodeID, &node.FileID, &node.Type, &node.ParentID,
+			&node.StartLine, &node.EndLine, &node.StartByte, &node.EndByte,
+			&node.Text, &attributesJSON, &node.Ticket, &node.SubtreeHash, &node.CreatedAt); err != nil {
+			return nil, "", err
+		}
+		if attributesJSON != nil {
+			if err := json.Unmarshal(attributesJSON, &node.Attributes); err != nil {
+				return nil, "", fmt.Errorf("failed to unmarshal attributes: %w", err)
+			}
+		}
+		nodes = append(nodes, &node)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, "", err
+	}
+
+	var nextCursor string
+	if len(nodes) > limit {
+		last := nodes[limit-1]
+		nextCursor = EncodeASTNodeCursor(last.StartLine, last.StartByte, last.NodeID)
+		nodes = nodes[:limit]
+	}
+
+	return nodes, nextCursor, nil
+}
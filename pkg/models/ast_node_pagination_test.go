@@ -0,0 +1,36 @@
+package models
+
+import "testing"
+
+func TestEncodeAndDecodeASTNodeCursorRoundTrip(t *testing.T) {
+	tests := []struct {
+		name      string
+		startLine int
+		startByte int
+		nodeID    string
+	}{
+		{name: "basic", startLine: 10, startByte: 200, nodeID: "11111111-1111-1111-1111-111111111111"},
+		{name: "zero position", startLine: 0, startByte: 0, nodeID: "22222222-2222-2222-2222-222222222222"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoded := EncodeASTNodeCursor(tt.startLine, tt.startByte, tt.nodeID)
+
+			startLine, startByte, nodeID, err := DecodeASTNodeCursor(encoded)
+			if err != nil {
+				t.Fatalf("DecodeASTNodeCursor(%q) returned error: %v", encoded, err)
+			}
+			if startLine != tt.startLine || startByte != tt.startByte || nodeID != tt.nodeID {
+				t.Errorf("round trip mismatch: got (%d, %d, %q), want (%d, %d, %q)",
+					startLine, startByte, nodeID, tt.startLine, tt.startByte, tt.nodeID)
+			}
+		})
+	}
+}
+
+func TestDecodeASTNodeCursorInvalid(t *testing.T) {
+	if _, _, _, err := DecodeASTNodeCursor("not-valid-base64!!!"); err == nil {
+		t.Error("expected an error for invalid base64, got nil")
+	}
+}
@@ -0,0 +1,604 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// MatchBinding maps each @capture name in a query pattern to the AST node it
+// matched against, for one match of that pattern against a file's tree.
+type MatchBinding map[string]*ASTNode
+
+// Query runs a tree-sitter-style S-expression pattern against the AST nodes
+// of fileID and returns one MatchBinding per match, keyed by the pattern's
+// @capture names. For example:
+//
+//	(function_declaration name: (identifier) @name body: (block (call_expression) @call))
+//
+// matches every function declaration, binding @name to its identifier node
+// and @call to any direct call_expression inside its body. Predicates of the
+// form (#eq? @name "foo") and (#match? @name "regex") filter matches by a
+// captured node's text.
+//
+// Patterns whose shape is a fixed-arity tree of typed nodes are compiled to
+// a single SQL query that self-joins ast_nodes once per pattern depth; this
+// covers the common case and keeps Query's cost proportional to the match
+// count rather than the file's node count. Patterns using tree-sitter
+// quantifiers (* + ?), alternation ([a b]), or anonymous string-literal
+// nodes can't be expressed as a fixed self-join, so those fall back to
+// queryByWalker, which evaluates the pattern against the file's nodes
+// in-memory (loaded once via GetByFileID).
+func (r *ASTNodeRepository) Query(ctx context.Context, fileID, pattern string) ([]MatchBinding, error) {
+	root, predicates, err := parseQueryPattern(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse query pattern: %w", err)
+	}
+
+	if root.needsWalkerFallback() {
+		return r.queryByWalker(ctx, fileID, root, predicates)
+	}
+	return r.queryBySQL(ctx, fileID, root, predicates)
+}
+
+// queryPattern is one node of a parsed S-expression pattern tree.
+type queryPattern struct {
+	Type string // tree-sitter node type to match; "_" matches any type
+	// Field is the tree-sitter field name this child is bound under. It's
+	// informational only: ast_nodes has no field-name column, so neither
+	// matcher enforces it.
+	Field      string
+	Capture    string // capture name without the leading "@"; "" if uncaptured
+	Anonymous  bool   // true for a quoted literal child, e.g. "+"
+	Quantifier byte   // 0, '*', '+', or '?' - suffix applied to this child
+	Alternates []*queryPattern // non-nil for a [a b c] alternation; Type/Children unused
+	Children   []*queryPattern
+}
+
+// needsWalkerFallback reports whether pattern (or any descendant) uses a
+// construct that can't be compiled into a fixed-depth SQL self-join.
+func (p *queryPattern) needsWalkerFallback() bool {
+	if p == nil {
+		return false
+	}
+	if p.Anonymous || p.Quantifier != 0 || p.Alternates != nil {
+		return true
+	}
+	for _, c := range p.Children {
+		if c.needsWalkerFallback() {
+			return true
+		}
+	}
+	return false
+}
+
+// queryPredicate is a (#eq? @cap "literal") or (#match? @cap "regex") clause.
+type queryPredicate struct {
+	Name    string // "eq" or "match"
+	Capture string
+	Value   string
+}
+
+// queryBySQL compiles pattern into a recursive self-join over ast_nodes,
+// one alias per depth level, and runs it. It is only reachable for patterns
+// that pass needsWalkerFallback's check, so every pattern node here matches
+// exactly one node at its position in the tree.
+func (r *ASTNodeRepository) queryBySQL(ctx context.Context, fileID string, root *queryPattern, predicates []queryPredicate) ([]MatchBinding, error) {
+	aliases := map[*queryPattern]string{}
+	var assignAliases func(p *queryPattern, next *int)
+	assignAliases = func(p *queryPattern, next *int) {
+		aliases[p] = fmt.Sprintf("n%d", *next)
+		*next++
+		for _, c := range p.Children {
+			assignAliases(c, next)
+		}
+	}
+	next := 0
+	assignAliases(root, &next)
+
+	var joins []string
+	var wheres []string
+	args := []interface{}{fileID}
+	argN := 2 // $1 is fileID
+
+	rootAlias := aliases[root]
+	joins = append(joins, fmt.Sprintf("ast_nodes %s", rootAlias))
+	wheres = append(wheres, fmt.Sprintf("%s.file_id = $1", rootAlias))
+	if root.Type != "" && root.Type != "_" {
+		wheres = append(wheres, fmt.Sprintf("%s.type = $%d", rootAlias, argN))
+		args = append(args, root.Type)
+		argN++
+	}
+
+	var walk func(p *queryPattern)
+	walk = func(p *queryPattern) {
+		parentAlias := aliases[p]
+		for _, child := range p.Children {
+			childAlias := aliases[child]
+			joins = append(joins, fmt.Sprintf("JOIN ast_nodes %s ON %s.parent_id = %s.node_id", childAlias, childAlias, parentAlias))
+			if child.Type != "" && child.Type != "_" {
+				wheres = append(wheres, fmt.Sprintf("%s.type = $%d", childAlias, argN))
+				args = append(args, child.Type)
+				argN++
+			}
+			walk(child)
+		}
+	}
+	walk(root)
+
+	for _, pred := range predicates {
+		alias := ""
+		for p, a := range aliases {
+			if p.Capture == pred.Capture {
+				alias = a
+				break
+			}
+		}
+		if alias == "" {
+			return nil, fmt.Errorf("predicate refers to unknown capture @%s", pred.Capture)
+		}
+		switch pred.Name {
+		case "eq":
+			wheres = append(wheres, fmt.Sprintf("%s.text = $%d", alias, argN))
+			args = append(args, pred.Value)
+			argN++
+		case "match":
+			wheres = append(wheres, fmt.Sprintf("%s.text ~ $%d", alias, argN))
+			args = append(args, pred.Value)
+			argN++
+		default:
+			return nil, fmt.Errorf("unsupported predicate #%s?", pred.Name)
+		}
+	}
+
+	var captures []*queryPattern
+	var collectCaptures func(p *queryPattern)
+	collectCaptures = func(p *queryPattern) {
+		if p.Capture != "" {
+			captures = append(captures, p)
+		}
+		for _, c := range p.Children {
+			collectCaptures(c)
+		}
+	}
+	collectCaptures(root)
+
+	selectCols := make([]string, 0, len(captures)*columnsPerASTNode)
+	for _, capNode := range captures {
+		alias := aliases[capNode]
+		for _, col := range astNodeColumns {
+			selectCols = append(selectCols, fmt.Sprintf("%s.%s", alias, col))
+		}
+	}
+	if len(captures) == 0 {
+		// No captures: report whether the root shape exists at all, bound
+		// under a synthetic "$match" key.
+		for _, col := range astNodeColumns {
+			selectCols = append(selectCols, fmt.Sprintf("%s.%s", rootAlias, col))
+		}
+	}
+
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE %s",
+		strings.Join(selectCols, ", "),
+		strings.Join(joins, " "),
+		strings.Join(wheres, " AND "))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query pattern: %w", err)
+	}
+	defer rows.Close()
+
+	captureNames := make([]string, len(captures))
+	for i, capNode := range captures {
+		captureNames[i] = capNode.Capture
+	}
+	if len(captures) == 0 {
+		captureNames = []string{"$match"}
+	}
+
+	var matches []MatchBinding
+	for rows.Next() {
+		binding := make(MatchBinding, len(captureNames))
+		nodes := make([]*ASTNode, len(captureNames))
+		attrsJSON := make([][]byte, len(captureNames))
+		scanArgs := make([]interface{}, 0, len(captureNames)*columnsPerASTNode)
+		for i := range nodes {
+			nodes[i] = &ASTNode{}
+			scanArgs = append(scanArgs,
+				&nodes[i].NodeID, &nodes[i].FileID, &nodes[i].Type, &nodes[i].ParentID,
+				&nodes[i].StartLine, &nodes[i].EndLine, &nodes[i].StartByte, &nodes[i].EndByte,
+				&nodes[i].Text, &attrsJSON[i], &nodes[i].Ticket, &nodes[i].SubtreeHash, &nodes[i].CreatedAt)
+		}
+		if err := rows.Scan(scanArgs...); err != nil {
+			return nil, fmt.Errorf("failed to scan query match: %w", err)
+		}
+		for i, name := range captureNames {
+			if attrsJSON[i] != nil {
+				if err := json.Unmarshal(attrsJSON[i], &nodes[i].Attributes); err != nil {
+					return nil, fmt.Errorf("failed to unmarshal attributes: %w", err)
+				}
+			}
+			binding[name] = nodes[i]
+		}
+		matches = append(matches, binding)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate query matches: %w", err)
+	}
+
+	return matches, nil
+}
+
+// astNodeColumns is the fixed column list queryBySQL selects per matched
+// alias, in the order queryBySQL scans them back in.
+var astNodeColumns = []string{
+	"node_id", "file_id", "type", "parent_id", "start_line", "end_line",
+	"start_byte", "end_byte", "text", "attributes", "ticket", "subtree_hash", "created_at",
+}
+
+const columnsPerASTNode = 13
+
+// queryByWalker evaluates pattern in memory against every node of fileID,
+// trying each node of a matching type as a possible root. Used for patterns
+// Query can't compile to a fixed SQL self-join (quantifiers, alternation,
+// anonymous literal children).
+func (r *ASTNodeRepository) queryByWalker(ctx context.Context, fileID string, root *queryPattern, predicates []queryPredicate) ([]MatchBinding, error) {
+	nodes, err := r.GetByFileID(ctx, fileID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load nodes for query: %w", err)
+	}
+
+	childrenOf := make(map[string][]*ASTNode)
+	for _, n := range nodes {
+		if n.ParentID != nil {
+			childrenOf[*n.ParentID] = append(childrenOf[*n.ParentID], n)
+		}
+	}
+	sortByPosition(nodes)
+	for _, kids := range childrenOf {
+		sortByPosition(kids)
+	}
+
+	var matches []MatchBinding
+	for _, n := range nodes {
+		binding := MatchBinding{}
+		if matchQueryPattern(root, n, childrenOf, binding) {
+			if predicatesHold(predicates, binding) {
+				matches = append(matches, binding)
+			}
+		}
+	}
+	return matches, nil
+}
+
+// matchQueryPattern tries to match pattern against node, recording any
+// captures into binding. It mutates binding even on a failed match; callers
+// only use binding after a true return.
+func matchQueryPattern(pattern *queryPattern, node *ASTNode, childrenOf map[string][]*ASTNode, binding MatchBinding) bool {
+	if pattern.Alternates != nil {
+		for _, alt := range pattern.Alternates {
+			if matchQueryPattern(alt, node, childrenOf, binding) {
+				return true
+			}
+		}
+		return false
+	}
+	if pattern.Anonymous {
+		if node.Text != pattern.Type {
+			return false
+		}
+	} else if pattern.Type != "" && pattern.Type != "_" && node.Type != pattern.Type {
+		return false
+	}
+
+	if pattern.Capture != "" {
+		binding[pattern.Capture] = node
+	}
+
+	return matchQueryChildren(pattern.Children, childrenOf[node.NodeID], childrenOf, binding)
+}
+
+// matchQueryChildren matches a pattern's child list against a node's actual
+// children, honoring each child's quantifier (default: exactly one).
+// Children need not be contiguous: patterns may skip over actual children
+// that aren't mentioned, mirroring tree-sitter query semantics.
+func matchQueryChildren(patterns []*queryPattern, actual []*ASTNode, childrenOf map[string][]*ASTNode, binding MatchBinding) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+
+	p := patterns[0]
+	rest := patterns[1:]
+
+	switch p.Quantifier {
+	case '*', '+':
+		matchedAny := false
+		i := 0
+		for ; i < len(actual); i++ {
+			if !matchQueryPattern(p, actual[i], childrenOf, binding) {
+				break
+			}
+			matchedAny = true
+		}
+		if p.Quantifier == '+' && !matchedAny {
+			return false
+		}
+		return matchQueryChildren(rest, actual[i:], childrenOf, binding)
+	case '?':
+		if len(actual) > 0 && matchQueryPattern(p, actual[0], childrenOf, binding) {
+			return matchQueryChildren(rest, actual[1:], childrenOf, binding)
+		}
+		return matchQueryChildren(rest, actual, childrenOf, binding)
+	default:
+		for i, a := range actual {
+			if matchQueryPattern(p, a, childrenOf, binding) {
+				if matchQueryChildren(rest, actual[i+1:], childrenOf, binding) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+}
+
+func predicatesHold(predicates []queryPredicate, binding MatchBinding) bool {
+	for _, pred := range predicates {
+		node, ok := binding[pred.Capture]
+		if !ok {
+			return false
+		}
+		switch pred.Name {
+		case "eq":
+			if node.Text != pred.Value {
+				return false
+			}
+		case "match":
+			matched, err := regexp.MatchString(pred.Value, node.Text)
+			if err != nil || !matched {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// parseQueryPattern parses a tree-sitter-style S-expression query pattern
+// into its root queryPattern plus any top-level (#eq?/#match?) predicates.
+func parseQueryPattern(pattern string) (*queryPattern, []queryPredicate, error) {
+	tokens, err := tokenizeQueryPattern(pattern)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, nil, fmt.Errorf("empty pattern")
+	}
+
+	parser := &queryPatternParser{tokens: tokens}
+	root, err := parser.parseExpr()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var predicates []queryPredicate
+	for parser.pos < len(parser.tokens) {
+		pred, err := parser.parsePredicate()
+		if err != nil {
+			return nil, nil, err
+		}
+		predicates = append(predicates, pred)
+	}
+
+	return root, predicates, nil
+}
+
+type queryToken struct {
+	kind string // "(", ")", "atom", "string", "capture", "field", "quant", "bracket_open", "bracket_close"
+	text string
+}
+
+// tokenizeQueryPattern splits a pattern into tokens. It's a small
+// hand-written scanner rather than a full tree-sitter query grammar, since
+// Query only needs to recognize node types, field names, captures, string
+// literals, quantifiers and [alternation] - not the full query language.
+func tokenizeQueryPattern(pattern string) ([]queryToken, error) {
+	var tokens []queryToken
+	i := 0
+	runes := []rune(pattern)
+	for i < len(runes) {
+		c := runes[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(':
+			tokens = append(tokens, queryToken{kind: "("})
+			i++
+		case c == ')':
+			tokens = append(tokens, queryToken{kind: ")"})
+			i++
+		case c == '[':
+			tokens = append(tokens, queryToken{kind: "bracket_open"})
+			i++
+		case c == ']':
+			tokens = append(tokens, queryToken{kind: "bracket_close"})
+			i++
+		case c == '*' || c == '+' || c == '?':
+			tokens = append(tokens, queryToken{kind: "quant", text: string(c)})
+			i++
+		case c == '"':
+			j := i + 1
+			for j < len(runes) && runes[j] != '"' {
+				j++
+			}
+			if j >= len(runes) {
+				return nil, fmt.Errorf("unterminated string literal")
+			}
+			tokens = append(tokens, queryToken{kind: "string", text: string(runes[i+1 : j])})
+			i = j + 1
+		case c == '@':
+			j := i + 1
+			for j < len(runes) && isQueryIdentChar(runes[j]) {
+				j++
+			}
+			tokens = append(tokens, queryToken{kind: "capture", text: string(runes[i+1 : j])})
+			i = j
+		default:
+			j := i
+			for j < len(runes) && (isQueryIdentChar(runes[j]) || runes[j] == '#') {
+				j++
+			}
+			if j == i {
+				return nil, fmt.Errorf("unexpected character %q at position %d", string(c), i)
+			}
+			text := string(runes[i:j])
+			if j < len(runes) && runes[j] == ':' {
+				tokens = append(tokens, queryToken{kind: "field", text: text})
+				j++
+			} else {
+				tokens = append(tokens, queryToken{kind: "atom", text: text})
+			}
+			i = j
+		}
+	}
+	return tokens, nil
+}
+
+func isQueryIdentChar(r rune) bool {
+	return r == '_' || r == '.' || r == '^' || r == '$' || r == '-' ||
+		(r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')
+}
+
+type queryPatternParser struct {
+	tokens []queryToken
+	pos    int
+}
+
+func (p *queryPatternParser) peek() (queryToken, bool) {
+	if p.pos >= len(p.tokens) {
+		return queryToken{}, false
+	}
+	return p.tokens[p.pos], true
+}
+
+// parseExpr parses one child position: a (node ...), a [alt1 alt2] group,
+// an anonymous "literal", or a bare wildcard "_" - optionally preceded by a
+// "field:" label and followed by a "@capture" and/or quantifier.
+func (p *queryPatternParser) parseExpr() (*queryPattern, error) {
+	tok, ok := p.peek()
+	if !ok {
+		return nil, fmt.Errorf("unexpected end of pattern")
+	}
+
+	result := &queryPattern{}
+	if tok.kind == "field" {
+		result.Field = tok.text
+		p.pos++
+		tok, ok = p.peek()
+		if !ok {
+			return nil, fmt.Errorf("expected node after field %q:", result.Field)
+		}
+	}
+
+	switch tok.kind {
+	case "(":
+		p.pos++
+		typeTok, ok := p.peek()
+		if !ok || typeTok.kind != "atom" {
+			return nil, fmt.Errorf("expected node type after '('")
+		}
+		result.Type = typeTok.text
+		p.pos++
+		for {
+			next, ok := p.peek()
+			if !ok {
+				return nil, fmt.Errorf("unterminated '(' for node %q", result.Type)
+			}
+			if next.kind == ")" {
+				p.pos++
+				break
+			}
+			child, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			result.Children = append(result.Children, child)
+		}
+	case "bracket_open":
+		p.pos++
+		for {
+			next, ok := p.peek()
+			if !ok {
+				return nil, fmt.Errorf("unterminated '['")
+			}
+			if next.kind == "bracket_close" {
+				p.pos++
+				break
+			}
+			alt, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			result.Alternates = append(result.Alternates, alt)
+		}
+	case "string":
+		result.Anonymous = true
+		result.Type = tok.text
+		p.pos++
+	case "atom":
+		result.Type = tok.text
+		p.pos++
+	default:
+		return nil, fmt.Errorf("unexpected token %q while parsing pattern", tok.text)
+	}
+
+	if capTok, ok := p.peek(); ok && capTok.kind == "capture" {
+		result.Capture = capTok.text
+		p.pos++
+	}
+	if quant, ok := p.peek(); ok && quant.kind == "quant" {
+		result.Quantifier = quant.text[0]
+		p.pos++
+	}
+
+	return result, nil
+}
+
+// parsePredicate parses a trailing (#eq? @capture "value") or
+// (#match? @capture "regex") clause.
+func (p *queryPatternParser) parsePredicate() (queryPredicate, error) {
+	if tok, ok := p.peek(); !ok || tok.kind != "(" {
+		return queryPredicate{}, fmt.Errorf("expected '(' to start predicate")
+	}
+	p.pos++
+
+	nameTok, ok := p.peek()
+	if !ok || nameTok.kind != "atom" || !strings.HasPrefix(nameTok.text, "#") {
+		return queryPredicate{}, fmt.Errorf("expected predicate name like #eq?")
+	}
+	name := strings.TrimSuffix(strings.TrimPrefix(nameTok.text, "#"), "?")
+	p.pos++
+
+	capTok, ok := p.peek()
+	if !ok || capTok.kind != "capture" {
+		return queryPredicate{}, fmt.Errorf("expected @capture in predicate #%s?", name)
+	}
+	p.pos++
+
+	valueTok, ok := p.peek()
+	if !ok || valueTok.kind != "string" {
+		return queryPredicate{}, fmt.Errorf("expected string value in predicate #%s?", name)
+	}
+	p.pos++
+
+	closeTok, ok := p.peek()
+	if !ok || closeTok.kind != ")" {
+		return queryPredicate{}, fmt.Errorf("expected ')' to close predicate #%s?", name)
+	}
+	p.pos++
+
+	return queryPredicate{Name: name, Capture: capTok.text, Value: valueTok.text}, nil
+}
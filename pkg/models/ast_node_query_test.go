@@ -0,0 +1,113 @@
+package models
+
+import "testing"
+
+func TestParseQueryPatternSimple(t *testing.T) {
+	root, predicates, err := parseQueryPattern(`(function_declaration name: (identifier) @name)`)
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+	if root.Type != "function_declaration" {
+		t.Fatalf("expected root type function_declaration, got %q", root.Type)
+	}
+	if len(root.Children) != 1 {
+		t.Fatalf("expected 1 child, got %d", len(root.Children))
+	}
+	child := root.Children[0]
+	if child.Field != "name" || child.Type != "identifier" || child.Capture != "name" {
+		t.Fatalf("unexpected child pattern: %+v", child)
+	}
+	if len(predicates) != 0 {
+		t.Fatalf("expected no predicates, got %+v", predicates)
+	}
+	if root.needsWalkerFallback() {
+		t.Fatalf("expected a fixed-shape pattern to be SQL-compilable")
+	}
+}
+
+func TestParseQueryPatternWithPredicate(t *testing.T) {
+	root, predicates, err := parseQueryPattern(`(identifier) @name (#eq? @name "foo")`)
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+	if root.Capture != "name" {
+		t.Fatalf("expected root capture name, got %q", root.Capture)
+	}
+	if len(predicates) != 1 || predicates[0].Name != "eq" || predicates[0].Capture != "name" || predicates[0].Value != "foo" {
+		t.Fatalf("unexpected predicates: %+v", predicates)
+	}
+}
+
+func TestParseQueryPatternQuantifierAndAlternationNeedWalker(t *testing.T) {
+	tests := []string{
+		`(block (statement)* @stmt)`,
+		`(call_expression [(identifier) (member_expression)] @callee)`,
+		`(binary_expression "+" @op)`,
+	}
+	for _, pattern := range tests {
+		root, _, err := parseQueryPattern(pattern)
+		if err != nil {
+			t.Fatalf("unexpected parse error for %q: %v", pattern, err)
+		}
+		if !root.needsWalkerFallback() {
+			t.Fatalf("expected pattern %q to require the walker fallback", pattern)
+		}
+	}
+}
+
+func TestQueryByWalkerMatchesNestedCalls(t *testing.T) {
+	nodes := []*ASTNode{
+		{NodeID: "fn", Type: "function_declaration"},
+		{NodeID: "name", Type: "identifier", Text: "DoWork", ParentID: strPtr("fn")},
+		{NodeID: "body", Type: "block", ParentID: strPtr("fn")},
+		{NodeID: "call1", Type: "call_expression", Text: "helper()", ParentID: strPtr("body")},
+		{NodeID: "other", Type: "return_statement", ParentID: strPtr("body")},
+	}
+
+	childrenOf := make(map[string][]*ASTNode)
+	for _, n := range nodes {
+		if n.ParentID != nil {
+			childrenOf[*n.ParentID] = append(childrenOf[*n.ParentID], n)
+		}
+	}
+
+	root, _, err := parseQueryPattern(`(function_declaration name: (identifier) @name body: (block (call_expression) @call))`)
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+
+	binding := MatchBinding{}
+	if !matchQueryPattern(root, nodes[0], childrenOf, binding) {
+		t.Fatalf("expected pattern to match function_declaration fn")
+	}
+	if binding["name"] == nil || binding["name"].NodeID != "name" {
+		t.Fatalf("expected @name bound to identifier node, got %+v", binding["name"])
+	}
+	if binding["call"] == nil || binding["call"].NodeID != "call1" {
+		t.Fatalf("expected @call bound to call_expression node, got %+v", binding["call"])
+	}
+}
+
+func TestQueryByWalkerRespectsEqPredicate(t *testing.T) {
+	nodes := []*ASTNode{
+		{NodeID: "a", Type: "identifier", Text: "foo"},
+		{NodeID: "b", Type: "identifier", Text: "bar"},
+	}
+	childrenOf := map[string][]*ASTNode{}
+
+	root, predicates, err := parseQueryPattern(`(identifier) @name (#eq? @name "foo")`)
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+
+	var matched []string
+	for _, n := range nodes {
+		binding := MatchBinding{}
+		if matchQueryPattern(root, n, childrenOf, binding) && predicatesHold(predicates, binding) {
+			matched = append(matched, n.NodeID)
+		}
+	}
+	if len(matched) != 1 || matched[0] != "a" {
+		t.Fatalf("expected only node a to match #eq? predicate, got %+v", matched)
+	}
+}
@@ -46,6 +46,35 @@ func (sm *SchemaManager) InitializeSchema(ctx context.Context) error {
 		}
 	}
 
+	// Add the ticket column/index to tables created before tickets existed
+	if err := sm.ensureTicketColumns(ctx); err != nil {
+		return fmt.Errorf("failed to ensure ticket columns: %w", err)
+	}
+
+	// Add and backfill the AST node closure table for databases created
+	// before it existed
+	if err := sm.ensureClosureTable(ctx); err != nil {
+		return fmt.Errorf("failed to ensure closure table: %w", err)
+	}
+
+	// Add the keyset-pagination index for databases created before
+	// ListPage/Stream* existed
+	if err := sm.ensurePaginationIndex(ctx); err != nil {
+		return fmt.Errorf("failed to ensure pagination index: %w", err)
+	}
+
+	// Add the subtree_hash column backing Diff for databases created before
+	// it existed
+	if err := sm.ensureSubtreeHashColumn(ctx); err != nil {
+		return fmt.Errorf("failed to ensure subtree_hash column: %w", err)
+	}
+
+	// Add the analysis_issues/analysis_incidents tables for databases
+	// created before AnalysisRepository existed
+	if err := sm.ensureAnalysisTables(ctx); err != nil {
+		return fmt.Errorf("failed to ensure analysis tables: %w", err)
+	}
+
 	if dbLogger != nil {
 		dbLogger.Debug("Database schema initialized successfully")
 	}
@@ -98,12 +127,14 @@ func (sm *SchemaManager) CreateSchema(ctx context.Context) error {
 			end_byte INT NOT NULL,
 			docstring TEXT,
 			semantic_summary TEXT,
+			ticket TEXT,
 			created_at TIMESTAMP DEFAULT NOW(),
 			UNIQUE(file_id, name, start_line, start_byte)
 		);
 		CREATE INDEX IF NOT EXISTS idx_symbols_file ON symbols(file_id);
 		CREATE INDEX IF NOT EXISTS idx_symbols_name ON symbols(name);
 		CREATE INDEX IF NOT EXISTS idx_symbols_kind ON symbols(kind);
+		CREATE INDEX IF NOT EXISTS idx_symbols_ticket ON symbols(ticket) WHERE ticket IS NOT NULL;
 
 		-- AST Nodes
 		CREATE TABLE IF NOT EXISTS ast_nodes (
@@ -117,11 +148,26 @@ func (sm *SchemaManager) CreateSchema(ctx context.Context) error {
 			end_byte INT NOT NULL,
 			text TEXT,
 			attributes JSONB,
+			ticket TEXT,
+			subtree_hash TEXT,
 			created_at TIMESTAMP DEFAULT NOW()
 		);
 		CREATE INDEX IF NOT EXISTS idx_ast_nodes_file ON ast_nodes(file_id);
 		CREATE INDEX IF NOT EXISTS idx_ast_nodes_parent ON ast_nodes(parent_id);
 		CREATE INDEX IF NOT EXISTS idx_ast_nodes_type ON ast_nodes(type);
+		CREATE INDEX IF NOT EXISTS idx_ast_nodes_ticket ON ast_nodes(ticket) WHERE ticket IS NOT NULL;
+		CREATE INDEX IF NOT EXISTS idx_ast_nodes_pagination ON ast_nodes(file_id, start_line, start_byte, node_id);
+		CREATE INDEX IF NOT EXISTS idx_ast_nodes_subtree_hash ON ast_nodes(subtree_hash) WHERE subtree_hash IS NOT NULL;
+
+		-- AST Node Closure (materialized ancestor/descendant pairs, maintained by ASTNodeRepository)
+		CREATE TABLE IF NOT EXISTS ast_node_closure (
+			ancestor_id UUID NOT NULL REFERENCES ast_nodes(node_id) ON DELETE CASCADE,
+			descendant_id UUID NOT NULL REFERENCES ast_nodes(node_id) ON DELETE CASCADE,
+			depth INT NOT NULL,
+			PRIMARY KEY (ancestor_id, descendant_id)
+		);
+		CREATE INDEX IF NOT EXISTS idx_ast_node_closure_descendant ON ast_node_closure(descendant_id, depth);
+		CREATE INDEX IF NOT EXISTS idx_ast_node_closure_ancestor ON ast_node_closure(ancestor_id, depth);
 
 		-- Dependency Edges
 		CREATE TABLE IF NOT EXISTS edges (
@@ -171,6 +217,33 @@ func (sm *SchemaManager) CreateSchema(ctx context.Context) error {
 			created_at TIMESTAMP DEFAULT NOW()
 		);
 		CREATE INDEX IF NOT EXISTS idx_summaries_entity ON summaries(entity_id, entity_type);
+
+		-- Analysis Issues (rule catalog for static-analysis findings)
+		CREATE TABLE IF NOT EXISTS analysis_issues (
+			issue_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+			rule_id VARCHAR(255) NOT NULL,
+			category VARCHAR(100) NOT NULL,
+			severity VARCHAR(50) NOT NULL,
+			message TEXT NOT NULL,
+			created_at TIMESTAMP DEFAULT NOW(),
+			UNIQUE(rule_id)
+		);
+		CREATE INDEX IF NOT EXISTS idx_analysis_issues_rule ON analysis_issues(rule_id);
+
+		-- Analysis Incidents (one finding at one AST node)
+		CREATE TABLE IF NOT EXISTS analysis_incidents (
+			incident_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+			issue_id UUID NOT NULL REFERENCES analysis_issues(issue_id) ON DELETE CASCADE,
+			node_id UUID NOT NULL REFERENCES ast_nodes(node_id) ON DELETE CASCADE,
+			file_id UUID NOT NULL REFERENCES files(file_id) ON DELETE CASCADE,
+			line INT NOT NULL,
+			column_number INT NOT NULL,
+			variables_json JSONB,
+			created_at TIMESTAMP DEFAULT NOW()
+		);
+		CREATE INDEX IF NOT EXISTS idx_analysis_incidents_file ON analysis_incidents(file_id);
+		CREATE INDEX IF NOT EXISTS idx_analysis_incidents_node ON analysis_incidents(node_id);
+		CREATE INDEX IF NOT EXISTS idx_analysis_incidents_issue ON analysis_incidents(issue_id);
 	`, vectorDim)
 
 	if _, err := sm.db.ExecContext(ctx, schema); err != nil {
@@ -259,6 +332,146 @@ func (sm *SchemaManager) ensureAGEGraph(ctx context.Context) error {
 	return nil
 }
 
+// ensureTicketColumns adds the ticket column and its unique index to
+// ast_nodes and symbols for databases created before tickets existed.
+// CreateSchema already declares these for fresh databases, so this is a
+// no-op there; ALTER TABLE ... ADD COLUMN IF NOT EXISTS keeps it idempotent.
+func (sm *SchemaManager) ensureTicketColumns(ctx context.Context) error {
+	statements := []string{
+		`ALTER TABLE ast_nodes ADD COLUMN IF NOT EXISTS ticket TEXT`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_ast_nodes_ticket ON ast_nodes(ticket) WHERE ticket IS NOT NULL`,
+		`ALTER TABLE symbols ADD COLUMN IF NOT EXISTS ticket TEXT`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_symbols_ticket ON symbols(ticket) WHERE ticket IS NOT NULL`,
+	}
+
+	for _, stmt := range statements {
+		if _, err := sm.db.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("failed to ensure ticket column: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// ensureClosureTable creates the ast_node_closure table for databases created
+// before it existed, then backfills it from the existing parent_id links.
+// CreateSchema already declares the table for fresh databases; the backfill
+// is safe to re-run since it only inserts rows that aren't already there.
+func (sm *SchemaManager) ensureClosureTable(ctx context.Context) error {
+	statements := []string{
+		`CREATE TABLE IF NOT EXISTS ast_node_closure (
+			ancestor_id UUID NOT NULL REFERENCES ast_nodes(node_id) ON DELETE CASCADE,
+			descendant_id UUID NOT NULL REFERENCES ast_nodes(node_id) ON DELETE CASCADE,
+			depth INT NOT NULL,
+			PRIMARY KEY (ancestor_id, descendant_id)
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_ast_node_closure_descendant ON ast_node_closure(descendant_id, depth)`,
+		`CREATE INDEX IF NOT EXISTS idx_ast_node_closure_ancestor ON ast_node_closure(ancestor_id, depth)`,
+		// Self rows (depth 0) for every existing node
+		`INSERT INTO ast_node_closure (ancestor_id, descendant_id, depth)
+			SELECT node_id, node_id, 0 FROM ast_nodes
+			ON CONFLICT (ancestor_id, descendant_id) DO NOTHING`,
+		// Ancestor rows derived from parent_id, one depth level at a time,
+		// until a pass adds no new rows (bounded by the tree's max depth)
+		`DO $$
+		DECLARE
+			inserted INT;
+		BEGIN
+			LOOP
+				INSERT INTO ast_node_closure (ancestor_id, descendant_id, depth)
+				SELECT c.ancestor_id, n.node_id, c.depth + 1
+				FROM ast_nodes n
+				JOIN ast_node_closure c ON c.descendant_id = n.parent_id
+				ON CONFLICT (ancestor_id, descendant_id) DO NOTHING;
+
+				GET DIAGNOSTICS inserted = ROW_COUNT;
+				EXIT WHEN inserted = 0;
+			END LOOP;
+		END $$`,
+	}
+
+	for _, stmt := range statements {
+		if _, err := sm.db.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("failed to ensure closure table: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// ensurePaginationIndex creates the composite index backing
+// ASTNodeRepository.ListPage's keyset pagination for databases created
+// before it existed. CreateSchema already declares it for fresh databases;
+// CREATE INDEX IF NOT EXISTS keeps this idempotent.
+func (sm *SchemaManager) ensurePaginationIndex(ctx context.Context) error {
+	_, err := sm.db.ExecContext(ctx, `
+		CREATE INDEX IF NOT EXISTS idx_ast_nodes_pagination
+		ON ast_nodes(file_id, start_line, start_byte, node_id)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to ensure pagination index: %w", err)
+	}
+	return nil
+}
+
+// ensureSubtreeHashColumn adds the subtree_hash column and its index to
+// ast_nodes for databases created before ASTNodeRepository.Diff existed.
+// CreateSchema already declares these for fresh databases; the ADD COLUMN
+// and CREATE INDEX statements are idempotent via IF NOT EXISTS.
+func (sm *SchemaManager) ensureSubtreeHashColumn(ctx context.Context) error {
+	statements := []string{
+		`ALTER TABLE ast_nodes ADD COLUMN IF NOT EXISTS subtree_hash TEXT`,
+		`CREATE INDEX IF NOT EXISTS idx_ast_nodes_subtree_hash ON ast_nodes(subtree_hash) WHERE subtree_hash IS NOT NULL`,
+	}
+
+	for _, stmt := range statements {
+		if _, err := sm.db.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("failed to ensure subtree_hash column: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// ensureAnalysisTables creates the analysis_issues/analysis_incidents tables
+// for databases created before AnalysisRepository existed. CreateSchema
+// already declares them for fresh databases; CREATE TABLE/INDEX IF NOT
+// EXISTS keeps this idempotent.
+func (sm *SchemaManager) ensureAnalysisTables(ctx context.Context) error {
+	statements := []string{
+		`CREATE TABLE IF NOT EXISTS analysis_issues (
+			issue_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+			rule_id VARCHAR(255) NOT NULL,
+			category VARCHAR(100) NOT NULL,
+			severity VARCHAR(50) NOT NULL,
+			message TEXT NOT NULL,
+			created_at TIMESTAMP DEFAULT NOW()
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_analysis_issues_rule ON analysis_issues(rule_id)`,
+		`CREATE TABLE IF NOT EXISTS analysis_incidents (
+			incident_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+			issue_id UUID NOT NULL REFERENCES analysis_issues(issue_id) ON DELETE CASCADE,
+			node_id UUID NOT NULL REFERENCES ast_nodes(node_id) ON DELETE CASCADE,
+			file_id UUID NOT NULL REFERENCES files(file_id) ON DELETE CASCADE,
+			line INT NOT NULL,
+			column_number INT NOT NULL,
+			variables_json JSONB,
+			created_at TIMESTAMP DEFAULT NOW()
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_analysis_incidents_file ON analysis_incidents(file_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_analysis_incidents_node ON analysis_incidents(node_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_analysis_incidents_issue ON analysis_incidents(issue_id)`,
+	}
+
+	for _, stmt := range statements {
+		if _, err := sm.db.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("failed to ensure analysis tables: %w", err)
+		}
+	}
+
+	return nil
+}
+
 // verifyCoreTables checks that all required tables exist
 func (sm *SchemaManager) verifyCoreTables(ctx context.Context) error {
 	requiredTables := []string{
@@ -266,6 +479,7 @@ func (sm *SchemaManager) verifyCoreTables(ctx context.Context) error {
 		"files",
 		"symbols",
 		"ast_nodes",
+		"ast_node_closure",
 		"edges",
 		"vectors",
 		"docstrings",
@@ -22,6 +22,7 @@ type Symbol struct {
 	EndByte         int       `json:"end_byte" db:"end_byte"`
 	Docstring       string    `json:"docstring" db:"docstring"`
 	SemanticSummary string    `json:"semantic_summary" db:"semantic_summary"`
+	Ticket          string    `json:"ticket,omitempty" db:"ticket"`
 	CreatedAt       time.Time `json:"created_at" db:"created_at"`
 }
 
@@ -38,16 +39,16 @@ func NewSymbolRepository(db *DB) *SymbolRepository {
 // Create inserts a new symbol record
 func (r *SymbolRepository) Create(ctx context.Context, symbol *Symbol) error {
 	query := `
-		INSERT INTO symbols (symbol_id, file_id, name, kind, signature, start_line, end_line, 
-			start_byte, end_byte, docstring, semantic_summary, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		INSERT INTO symbols (symbol_id, file_id, name, kind, signature, start_line, end_line,
+			start_byte, end_byte, docstring, semantic_summary, ticket, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
 	`
 	symbol.CreatedAt = time.Now()
 
 	_, err := r.db.ExecContext(ctx, query,
 		symbol.SymbolID, symbol.FileID, symbol.Name, symbol.Kind, symbol.Signature,
 		symbol.StartLine, symbol.EndLine, symbol.StartByte, symbol.EndByte,
-		symbol.Docstring, symbol.SemanticSummary, symbol.CreatedAt)
+		symbol.Docstring, symbol.SemanticSummary, symbol.Ticket, symbol.CreatedAt)
 	return err
 }
 
@@ -55,14 +56,14 @@ func (r *SymbolRepository) Create(ctx context.Context, symbol *Symbol) error {
 func (r *SymbolRepository) GetByID(ctx context.Context, symbolID string) (*Symbol, error) {
 	query := `
 		SELECT symbol_id, file_id, name, kind, signature, start_line, end_line,
-			start_byte, end_byte, docstring, semantic_summary, created_at
+			start_byte, end_byte, docstring, semantic_summary, ticket, created_at
 		FROM symbols WHERE symbol_id = $1
 	`
 	var symbol Symbol
 	err := r.db.QueryRowContext(ctx, query, symbolID).Scan(
 		&symbol.SymbolID, &symbol.FileID, &symbol.Name, &symbol.Kind, &symbol.Signature,
 		&symbol.StartLine, &symbol.EndLine, &symbol.StartByte, &symbol.EndByte,
-		&symbol.Docstring, &symbol.SemanticSummary, &symbol.CreatedAt)
+		&symbol.Docstring, &symbol.SemanticSummary, &symbol.Ticket, &symbol.CreatedAt)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
@@ -76,7 +77,7 @@ func (r *SymbolRepository) GetByID(ctx context.Context, symbolID string) (*Symbo
 func (r *SymbolRepository) GetByFileID(ctx context.Context, fileID string) ([]*Symbol, error) {
 	query := `
 		SELECT symbol_id, file_id, name, kind, signature, start_line, end_line,
-			start_byte, end_byte, docstring, semantic_summary, created_at
+			start_byte, end_byte, docstring, semantic_summary, ticket, created_at
 		FROM symbols WHERE file_id = $1 ORDER BY start_line, start_byte
 	`
 	rows, err := r.db.QueryContext(ctx, query, fileID)
@@ -91,7 +92,7 @@ func (r *SymbolRepository) GetByFileID(ctx context.Context, fileID string) ([]*S
 		err := rows.Scan(
 			&symbol.SymbolID, &symbol.FileID, &symbol.Name, &symbol.Kind, &symbol.Signature,
 			&symbol.StartLine, &symbol.EndLine, &symbol.StartByte, &symbol.EndByte,
-			&symbol.Docstring, &symbol.SemanticSummary, &symbol.CreatedAt)
+			&symbol.Docstring, &symbol.SemanticSummary, &symbol.Ticket, &symbol.CreatedAt)
 		if err != nil {
 			return nil, err
 		}
@@ -104,7 +105,7 @@ func (r *SymbolRepository) GetByFileID(ctx context.Context, fileID string) ([]*S
 func (r *SymbolRepository) GetByKind(ctx context.Context, fileID, kind string) ([]*Symbol, error) {
 	query := `
 		SELECT symbol_id, file_id, name, kind, signature, start_line, end_line,
-			start_byte, end_byte, docstring, semantic_summary, created_at
+			start_byte, end_byte, docstring, semantic_summary, ticket, created_at
 		FROM symbols WHERE file_id = $1 AND kind = $2 ORDER BY start_line, start_byte
 	`
 	rows, err := r.db.QueryContext(ctx, query, fileID, kind)
@@ -119,7 +120,7 @@ func (r *SymbolRepository) GetByKind(ctx context.Context, fileID, kind string) (
 		err := rows.Scan(
 			&symbol.SymbolID, &symbol.FileID, &symbol.Name, &symbol.Kind, &symbol.Signature,
 			&symbol.StartLine, &symbol.EndLine, &symbol.StartByte, &symbol.EndByte,
-			&symbol.Docstring, &symbol.SemanticSummary, &symbol.CreatedAt)
+			&symbol.Docstring, &symbol.SemanticSummary, &symbol.Ticket, &symbol.CreatedAt)
 		if err != nil {
 			return nil, err
 		}
@@ -132,7 +133,7 @@ func (r *SymbolRepository) GetByKind(ctx context.Context, fileID, kind string) (
 func (r *SymbolRepository) GetByName(ctx context.Context, namePattern string) ([]*Symbol, error) {
 	query := `
 		SELECT symbol_id, file_id, name, kind, signature, start_line, end_line,
-			start_byte, end_byte, docstring, semantic_summary, created_at
+			start_byte, end_byte, docstring, semantic_summary, ticket, created_at
 		FROM symbols WHERE name ILIKE $1 ORDER BY name
 	`
 	rows, err := r.db.QueryContext(ctx, query, namePattern)
@@ -147,7 +148,7 @@ func (r *SymbolRepository) GetByName(ctx context.Context, namePattern string) ([
 		err := rows.Scan(
 			&symbol.SymbolID, &symbol.FileID, &symbol.Name, &symbol.Kind, &symbol.Signature,
 			&symbol.StartLine, &symbol.EndLine, &symbol.StartByte, &symbol.EndByte,
-			&symbol.Docstring, &symbol.SemanticSummary, &symbol.CreatedAt)
+			&symbol.Docstring, &symbol.SemanticSummary, &symbol.Ticket, &symbol.CreatedAt)
 		if err != nil {
 			return nil, err
 		}
@@ -161,13 +162,13 @@ func (r *SymbolRepository) Update(ctx context.Context, symbol *Symbol) error {
 	query := `
 		UPDATE symbols 
 		SET name = $3, kind = $4, signature = $5, start_line = $6, end_line = $7,
-			start_byte = $8, end_byte = $9, docstring = $10, semantic_summary = $11
+			start_byte = $8, end_byte = $9, docstring = $10, semantic_summary = $11, ticket = $12
 		WHERE symbol_id = $1 AND file_id = $2
 	`
 	result, err := r.db.ExecContext(ctx, query,
 		symbol.SymbolID, symbol.FileID, symbol.Name, symbol.Kind, symbol.Signature,
 		symbol.StartLine, symbol.EndLine, symbol.StartByte, symbol.EndByte,
-		symbol.Docstring, symbol.SemanticSummary)
+		symbol.Docstring, symbol.SemanticSummary, symbol.Ticket)
 	if err != nil {
 		return err
 	}
@@ -208,8 +209,8 @@ func (r *SymbolRepository) BatchCreate(ctx context.Context, symbols []*Symbol) e
 
 	query := `
 		INSERT INTO symbols (symbol_id, file_id, name, kind, signature, start_line, end_line,
-			start_byte, end_byte, docstring, semantic_summary, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+			start_byte, end_byte, docstring, semantic_summary, ticket, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
 		ON CONFLICT (file_id, name, start_line, start_byte) 
 		DO UPDATE SET 
 			kind = EXCLUDED.kind,
@@ -217,7 +218,8 @@ func (r *SymbolRepository) BatchCreate(ctx context.Context, symbols []*Symbol) e
 			end_line = EXCLUDED.end_line,
 			end_byte = EXCLUDED.end_byte,
 			docstring = EXCLUDED.docstring,
-			semantic_summary = EXCLUDED.semantic_summary
+			semantic_summary = EXCLUDED.semantic_summary,
+			ticket = EXCLUDED.ticket
 	`
 
 	stmt, err := r.db.PrepareContext(ctx, query)
@@ -232,7 +234,7 @@ func (r *SymbolRepository) BatchCreate(ctx context.Context, symbols []*Symbol) e
 		_, err := stmt.ExecContext(ctx,
 			symbol.SymbolID, symbol.FileID, symbol.Name, symbol.Kind, symbol.Signature,
 			symbol.StartLine, symbol.EndLine, symbol.StartByte, symbol.EndByte,
-			symbol.Docstring, symbol.SemanticSummary, symbol.CreatedAt)
+			symbol.Docstring, symbol.SemanticSummary, symbol.Ticket, symbol.CreatedAt)
 		if err != nil {
 			return fmt.Errorf("failed to insert symbol %s: %w", symbol.Name, err)
 		}
@@ -249,8 +251,8 @@ func (r *SymbolRepository) BatchCreateTx(ctx context.Context, tx *sql.Tx, symbol
 
 	query := `
 		INSERT INTO symbols (symbol_id, file_id, name, kind, signature, start_line, end_line,
-			start_byte, end_byte, docstring, semantic_summary, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+			start_byte, end_byte, docstring, semantic_summary, ticket, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
 		ON CONFLICT (file_id, name, start_line, start_byte) 
 		DO UPDATE SET 
 			kind = EXCLUDED.kind,
@@ -258,7 +260,8 @@ func (r *SymbolRepository) BatchCreateTx(ctx context.Context, tx *sql.Tx, symbol
 			end_line = EXCLUDED.end_line,
 			end_byte = EXCLUDED.end_byte,
 			docstring = EXCLUDED.docstring,
-			semantic_summary = EXCLUDED.semantic_summary
+			semantic_summary = EXCLUDED.semantic_summary,
+			ticket = EXCLUDED.ticket
 	`
 
 	stmt, err := tx.PrepareContext(ctx, query)
@@ -273,7 +276,7 @@ func (r *SymbolRepository) BatchCreateTx(ctx context.Context, tx *sql.Tx, symbol
 		_, err := stmt.ExecContext(ctx,
 			symbol.SymbolID, symbol.FileID, symbol.Name, symbol.Kind, symbol.Signature,
 			symbol.StartLine, symbol.EndLine, symbol.StartByte, symbol.EndByte,
-			symbol.Docstring, symbol.SemanticSummary, symbol.CreatedAt)
+			symbol.Docstring, symbol.SemanticSummary, symbol.Ticket, symbol.CreatedAt)
 		if err != nil {
 			return fmt.Errorf("failed to insert symbol %s: %w", symbol.Name, err)
 		}
@@ -293,7 +296,7 @@ func (r *SymbolRepository) DeleteByFileID(ctx context.Context, fileID string) er
 func (r *SymbolRepository) GetSymbolsWithDocstrings(ctx context.Context, fileID string) ([]*Symbol, error) {
 	query := `
 		SELECT symbol_id, file_id, name, kind, signature, start_line, end_line,
-			start_byte, end_byte, docstring, semantic_summary, created_at
+			start_byte, end_byte, docstring, semantic_summary, ticket, created_at
 		FROM symbols 
 		WHERE file_id = $1 AND docstring IS NOT NULL AND docstring != ''
 		ORDER BY start_line, start_byte
@@ -310,7 +313,7 @@ func (r *SymbolRepository) GetSymbolsWithDocstrings(ctx context.Context, fileID
 		err := rows.Scan(
 			&symbol.SymbolID, &symbol.FileID, &symbol.Name, &symbol.Kind, &symbol.Signature,
 			&symbol.StartLine, &symbol.EndLine, &symbol.StartByte, &symbol.EndByte,
-			&symbol.Docstring, &symbol.SemanticSummary, &symbol.CreatedAt)
+			&symbol.Docstring, &symbol.SemanticSummary, &symbol.Ticket, &symbol.CreatedAt)
 		if err != nil {
 			return nil, err
 		}
@@ -327,7 +330,7 @@ func (r *SymbolRepository) GetSymbolsByKinds(ctx context.Context, fileID string,
 
 	query := `
 		SELECT symbol_id, file_id, name, kind, signature, start_line, end_line,
-			start_byte, end_byte, docstring, semantic_summary, created_at
+			start_byte, end_byte, docstring, semantic_summary, ticket, created_at
 		FROM symbols 
 		WHERE file_id = $1 AND kind = ANY($2)
 		ORDER BY start_line, start_byte
@@ -344,7 +347,7 @@ func (r *SymbolRepository) GetSymbolsByKinds(ctx context.Context, fileID string,
 		err := rows.Scan(
 			&symbol.SymbolID, &symbol.FileID, &symbol.Name, &symbol.Kind, &symbol.Signature,
 			&symbol.StartLine, &symbol.EndLine, &symbol.StartByte, &symbol.EndByte,
-			&symbol.Docstring, &symbol.SemanticSummary, &symbol.CreatedAt)
+			&symbol.Docstring, &symbol.SemanticSummary, &symbol.Ticket, &symbol.CreatedAt)
 		if err != nil {
 			return nil, err
 		}
@@ -387,3 +390,25 @@ func (r *SymbolRepository) CountByKind(ctx context.Context, fileID string) (map[
 	}
 	return counts, rows.Err()
 }
+
+// GetByTicket retrieves a symbol by its canonical ticket (see FormatTicket).
+// It returns (nil, nil) if no symbol has that ticket.
+func (r *SymbolRepository) GetByTicket(ctx context.Context, ticket string) (*Symbol, error) {
+	query := `
+		SELECT symbol_id, file_id, name, kind, signature, start_line, end_line,
+			start_byte, end_byte, docstring, semantic_summary, ticket, created_at
+		FROM symbols WHERE ticket = $1
+	`
+	var symbol Symbol
+	err := r.db.QueryRowContext(ctx, query, ticket).Scan(
+		&symbol.SymbolID, &symbol.FileID, &symbol.Name, &symbol.Kind, &symbol.Signature,
+		&symbol.StartLine, &symbol.EndLine, &symbol.StartByte, &symbol.EndByte,
+		&symbol.Docstring, &symbol.SemanticSummary, &symbol.Ticket, &symbol.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &symbol, nil
+}
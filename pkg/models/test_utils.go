@@ -18,7 +18,7 @@ type TestDB struct {
 
 // SetupTestDB creates a test database and returns a connection
 // This should be called at the beginning of each test that requires database access
-func SetupTestDB(t *testing.T) *TestDB {
+func SetupTestDB(t testing.TB) *TestDB {
 	t.Helper()
 
 	// Disable database logging during tests to reduce noise
@@ -82,7 +82,7 @@ func SetupTestDB(t *testing.T) *TestDB {
 }
 
 // TeardownTestDB drops the test database and closes the connection
-func (tdb *TestDB) TeardownTestDB(t *testing.T) {
+func (tdb *TestDB) TeardownTestDB(t testing.TB) {
 	t.Helper()
 
 	dbName := tdb.dbName
@@ -0,0 +1,119 @@
+package models
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Ticket is a Kythe VName-inspired, URI-addressable identifier for an
+// ASTNode or Symbol: kythe://corpus?lang=language?path=path?root=root#signature
+//
+// Unlike the node_id/symbol_id UUIDs (which are regenerated on every parse),
+// a ticket's Signature is derived from properties that stay stable across
+// re-parses, so external tools can deep-link into the knowledge graph
+// without depending on internal storage IDs.
+type Ticket struct {
+	Corpus    string // repository name
+	Language  string
+	Path      string // file path relative to the repository root
+	Root      string // sub-root within the corpus; usually empty
+	Signature string // stable identity within (corpus, language, path, root)
+}
+
+// FormatTicket renders t as a canonical ticket string.
+func FormatTicket(t Ticket) string {
+	var b strings.Builder
+	b.WriteString("kythe://")
+	b.WriteString(url.PathEscape(t.Corpus))
+	if t.Language != "" {
+		fmt.Fprintf(&b, "?lang=%s", url.QueryEscape(t.Language))
+	}
+	if t.Path != "" {
+		fmt.Fprintf(&b, "?path=%s", url.QueryEscape(t.Path))
+	}
+	if t.Root != "" {
+		fmt.Fprintf(&b, "?root=%s", url.QueryEscape(t.Root))
+	}
+	if t.Signature != "" {
+		b.WriteByte('#')
+		b.WriteString(url.QueryEscape(t.Signature))
+	}
+	return b.String()
+}
+
+// ParseTicket parses a ticket string produced by FormatTicket.
+func ParseTicket(ticket string) (Ticket, error) {
+	const scheme = "kythe://"
+	if !strings.HasPrefix(ticket, scheme) {
+		return Ticket{}, fmt.Errorf("invalid ticket %q: missing %q scheme", ticket, scheme)
+	}
+	rest := strings.TrimPrefix(ticket, scheme)
+
+	var signature string
+	if idx := strings.IndexByte(rest, '#'); idx >= 0 {
+		var err error
+		signature, err = url.QueryUnescape(rest[idx+1:])
+		if err != nil {
+			return Ticket{}, fmt.Errorf("invalid ticket %q: bad signature: %w", ticket, err)
+		}
+		rest = rest[:idx]
+	}
+
+	corpusPart := rest
+	var query string
+	if idx := strings.IndexByte(rest, '?'); idx >= 0 {
+		corpusPart = rest[:idx]
+		query = rest[idx+1:]
+	}
+
+	corpus, err := url.PathUnescape(corpusPart)
+	if err != nil {
+		return Ticket{}, fmt.Errorf("invalid ticket %q: bad corpus: %w", ticket, err)
+	}
+	t := Ticket{Corpus: corpus, Signature: signature}
+
+	for _, segment := range strings.Split(query, "?") {
+		if segment == "" {
+			continue
+		}
+		kv := strings.SplitN(segment, "=", 2)
+		if len(kv) != 2 {
+			return Ticket{}, fmt.Errorf("invalid ticket %q: malformed query segment %q", ticket, segment)
+		}
+		value, err := url.QueryUnescape(kv[1])
+		if err != nil {
+			return Ticket{}, fmt.Errorf("invalid ticket %q: %w", ticket, err)
+		}
+		switch kv[0] {
+		case "lang":
+			t.Language = value
+		case "path":
+			t.Path = value
+		case "root":
+			t.Root = value
+		default:
+			return Ticket{}, fmt.Errorf("invalid ticket %q: unknown query key %q", ticket, kv[0])
+		}
+	}
+
+	return t, nil
+}
+
+// ASTNodeSignature derives a stable ticket signature for an AST node from
+// its type and full span, rather than its randomly generated node_id, so the
+// ticket survives re-parsing as long as the node's shape doesn't change.
+// startByte alone is not enough to disambiguate: nested same-type nodes that
+// start at the same offset (e.g. left-associative chains like a.b.c, or
+// a+b+c) share type, startLine, and startByte but differ in extent, so
+// endByte is included too.
+func ASTNodeSignature(nodeType string, startLine, startByte, endByte int) string {
+	return fmt.Sprintf("node:%s:%d:%d:%d", nodeType, startLine, startByte, endByte)
+}
+
+// SymbolSignature derives a stable ticket signature for a symbol from its
+// kind, name, declaration line, and start byte; the start byte disambiguates
+// symbols that share a kind, name, and line (e.g. overloaded declarations).
+func SymbolSignature(kind, name string, startLine, startByte int) string {
+	return fmt.Sprintf("symbol:%s:%s:%d:%d", kind, name, startLine, startByte)
+}
@@ -0,0 +1,107 @@
+package models
+
+import "testing"
+
+func TestFormatAndParseTicketRoundTrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		ticket Ticket
+	}{
+		{
+			name: "full ticket",
+			ticket: Ticket{
+				Corpus:    "yourtion/CodeAtlas",
+				Language:  "go",
+				Path:      "pkg/foo.go",
+				Root:      "vendor",
+				Signature: "symbol:function:Foo:10",
+			},
+		},
+		{
+			name: "no root",
+			ticket: Ticket{
+				Corpus:    "yourtion/CodeAtlas",
+				Language:  "go",
+				Path:      "pkg/foo.go",
+				Signature: "node:call_expression:10:200",
+			},
+		},
+		{
+			name:   "empty corpus",
+			ticket: Ticket{Path: "a.py", Language: "python", Signature: "symbol:variable:x:1"},
+		},
+		{
+			name: "path with special characters",
+			ticket: Ticket{
+				Corpus:    "repo",
+				Language:  "javascript",
+				Path:      "src/a b/c#d.js",
+				Signature: "symbol:function:f:1",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			formatted := FormatTicket(tt.ticket)
+
+			parsed, err := ParseTicket(formatted)
+			if err != nil {
+				t.Fatalf("ParseTicket(%q) returned error: %v", formatted, err)
+			}
+
+			if parsed != tt.ticket {
+				t.Errorf("round trip mismatch: got %+v, want %+v", parsed, tt.ticket)
+			}
+		})
+	}
+}
+
+func TestParseTicketErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		ticket string
+	}{
+		{name: "missing scheme", ticket: "repo?lang=go#sig"},
+		{name: "unknown query key", ticket: "kythe://repo?weird=value#sig"},
+		{name: "malformed query segment", ticket: "kythe://repo?lang#sig"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseTicket(tt.ticket); err == nil {
+				t.Errorf("ParseTicket(%q) expected an error, got nil", tt.ticket)
+			}
+		})
+	}
+}
+
+func TestASTNodeSignatureStableAcrossRandomNodeIDs(t *testing.T) {
+	sig1 := ASTNodeSignature("call_expression", 42, 1000, 1010)
+	sig2 := ASTNodeSignature("call_expression", 42, 1000, 1010)
+
+	if sig1 != sig2 {
+		t.Errorf("expected identical signatures for identical node shape, got %q and %q", sig1, sig2)
+	}
+
+	if sig3 := ASTNodeSignature("call_expression", 43, 1000, 1010); sig3 == sig1 {
+		t.Errorf("expected different signature for different start line")
+	}
+
+	if sig4 := ASTNodeSignature("call_expression", 42, 1000, 1020); sig4 == sig1 {
+		t.Errorf("expected different signature for different end byte (e.g. nested same-type nodes sharing a start position)")
+	}
+}
+
+func TestSymbolSignatureDeterministic(t *testing.T) {
+	sig1 := SymbolSignature("function", "Foo", 10, 100)
+	sig2 := SymbolSignature("function", "Foo", 10, 100)
+
+	if sig1 != sig2 {
+		t.Errorf("expected identical signatures for identical symbol identity, got %q and %q", sig1, sig2)
+	}
+
+	if sig3 := SymbolSignature("function", "Bar", 10, 100); sig3 == sig1 {
+		t.Errorf("expected different signature for different symbol name")
+	}
+}